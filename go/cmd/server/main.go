@@ -3,10 +3,15 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/0xPARC/parcnet/go/pod"
 	"github.com/google/uuid"
@@ -15,11 +20,14 @@ import (
 )
 
 var (
-	privateKey = os.Getenv("PRIVATE_KEY")
+	privateKey string
 	rdb        *redis.Client
 	ctx        = context.Background()
 )
 
+// Largest request body read by handlers which accept a POD.
+const maxRequestBytes = 1 << 20
+
 func handleRoot(w http.ResponseWriter, r *http.Request) {
 	// To prevent fallback to the root from requests like /favicon.ico
 	if r.URL.Path != "/" {
@@ -49,7 +57,7 @@ func handleRoot(w http.ResponseWriter, r *http.Request) {
 }
 
 type signRequest struct {
-	Entries pod.PodEntries `json:"entries"`
+	Entries json.RawMessage `json:"entries"`
 }
 
 func handleSign(w http.ResponseWriter, r *http.Request) {
@@ -64,9 +72,9 @@ func handleSign(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	podInstance, err := pod.CreatePod(privateKey, req.Entries)
+	podInstance, err := pod.CreatePodFromJSON(privateKey, req.Entries)
 	if err != nil {
-		http.Error(w, "Error creating POD: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Error creating POD: "+err.Error(), createPodErrorStatus(err))
 		return
 	}
 
@@ -80,6 +88,16 @@ func handleSign(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(jsonPod)
 }
 
+// Returns the HTTP status for an error from pod.CreatePodFromJSON.  A bad
+// server key is the server's fault, while anything else is a problem with the
+// submitted entries.
+func createPodErrorStatus(err error) int {
+	if errors.Is(err, pod.ErrInvalidPrivateKey) {
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
+
 type verifyResponse struct {
 	IsValid bool   `json:"isValid"`
 	Error   string `json:"error,omitempty"`
@@ -91,11 +109,17 @@ func handleVerify(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var p pod.Pod
-	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err != nil {
+		http.Error(w, "Error reading request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ok, verr := pod.VerifyJSON(body)
+	if errors.Is(verr, pod.ErrMalformedPod) {
 		response := verifyResponse{
 			IsValid: false,
-			Error:   "Invalid POD JSON: " + err.Error(),
+			Error:   "Invalid POD JSON: " + verr.Error(),
 		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
@@ -103,8 +127,6 @@ func handleVerify(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ok, verr := p.Verify()
-
 	response := verifyResponse{
 		IsValid: ok && verr == nil,
 	}
@@ -118,6 +140,220 @@ func handleVerify(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(response)
 }
 
+type versionResponse struct {
+	Version     string `json:"version"`
+	SpecVersion string `json:"specVersion"`
+}
+
+// Report the POD library and format versions, to help diagnose mismatches with
+// other POD implementations.
+func handleVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, r.Method+" not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(versionResponse{
+		Version:     pod.Version(),
+		SpecVersion: pod.SpecVersion(),
+	})
+}
+
+// How long an issued challenge nonce can be used before it expires
+const challengeTTL = 5 * time.Minute
+
+// Name of the POD entry which must echo the challenge nonce
+const challengeNonceEntry = "nonce"
+
+type challengeResponse struct {
+	Nonce string `json:"nonce"`
+}
+
+// Issue a single-use nonce which the client must include in a signed POD
+// submitted to /verify-challenge.
+func handleChallenge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, r.Method+" not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	nonce := uuid.New().String()
+	if err := rdb.Set(ctx, "challenge:"+nonce, 1, challengeTTL).Err(); err != nil {
+		http.Error(w, "Error storing challenge: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(challengeResponse{Nonce: nonce})
+}
+
+type verifyChallengeRequest struct {
+	Nonce string  `json:"nonce"`
+	Pod   pod.Pod `json:"pod"`
+}
+
+// Verify a POD echoing a nonce issued by /challenge.  The nonce is consumed on
+// success, so the same POD can't be replayed.
+func handleVerifyChallenge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, r.Method+" not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req verifyChallengeRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		response := verifyResponse{
+			IsValid: false,
+			Error:   "Invalid challenge JSON: " + err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	response := verifyResponse{}
+	challengeKey := "challenge:" + req.Nonce
+	issued, err := rdb.Exists(ctx, challengeKey).Result()
+	if err != nil {
+		http.Error(w, "Error looking up challenge: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if issued == 0 {
+		response.Error = "unknown or expired nonce"
+	} else {
+		ok, verr := req.Pod.VerifyChallenge(challengeNonceEntry, req.Nonce)
+		if verr != nil {
+			response.Error = verr.Error()
+		} else if ok {
+			// Only the request which deletes the nonce succeeds, so concurrent
+			// submissions of the same POD can't both be accepted.
+			deleted, err := rdb.Del(ctx, challengeKey).Result()
+			if err != nil {
+				http.Error(w, "Error consuming challenge: "+err.Error(), http.StatusInternalServerError)
+				return
+			}
+			response.IsValid = deleted == 1
+			if !response.IsValid {
+				response.Error = "nonce already used"
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+type debugPodResponse struct {
+	CanonicalPod json.RawMessage `json:"canonicalPod,omitempty"`
+	ContentID    string          `json:"contentId,omitempty"`
+	InputFormat  podInputFormat  `json:"inputFormat"`
+	IsValid      bool            `json:"isValid"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// Encodings detected in a submitted POD's JSON
+type podInputFormat struct {
+	Entries         []string `json:"entries"`
+	Signature       string   `json:"signature"`
+	SignerPublicKey string   `json:"signerPublicKey"`
+}
+
+// Echo back how a POD was parsed, for client developers debugging their POD
+// construction.  Only registered when ENABLE_DEBUG_ENDPOINTS=true.
+func handleDebugPod(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, r.Method+" not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err != nil {
+		http.Error(w, "Error reading body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := debugPodResponse{InputFormat: detectPodInputFormat(body)}
+	var p pod.Pod
+	if err := json.Unmarshal(body, &p); err != nil {
+		response.Error = "Invalid POD JSON: " + err.Error()
+	} else {
+		if contentID, err := p.ContentIDHex(); err == nil {
+			response.ContentID = contentID
+		}
+		ok, verr := p.Verify()
+		response.IsValid = ok && verr == nil
+		if verr != nil {
+			response.Error = verr.Error()
+		}
+		if _, err := p.Normalize(); err == nil {
+			response.CanonicalPod, _ = json.Marshal(p)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func detectPodInputFormat(body []byte) podInputFormat {
+	var raw struct {
+		Entries         map[string]json.RawMessage `json:"entries"`
+		Signature       string                     `json:"signature"`
+		SignerPublicKey string                     `json:"signerPublicKey"`
+	}
+	format := podInputFormat{Entries: []string{}}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return format
+	}
+
+	entryFormats := map[string]bool{}
+	for _, value := range raw.Entries {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(value, &object); err != nil {
+			entryFormats["terse"] = true
+		} else if _, hasType := object["type"]; hasType && len(object) == 2 {
+			entryFormats["typed"] = true
+		} else {
+			entryFormats["explicit"] = true
+		}
+	}
+	for entryFormat := range entryFormats {
+		format.Entries = append(format.Entries, entryFormat)
+	}
+	sort.Strings(format.Entries)
+
+	format.Signature = detectBytesEncoding(raw.Signature, 64)
+	format.SignerPublicKey = detectBytesEncoding(raw.SignerPublicKey, 32)
+	return format
+}
+
+// Names the encoding of a signature or public key, matching the hex and
+// Base64 variants accepted by pod.DecodeBytes.
+func detectBytesEncoding(encoded string, expectedBytes int) string {
+	encoding := "base64"
+	if strings.ContainsAny(encoded, "-_") {
+		encoding = "base64url"
+	}
+	switch {
+	case encoded == "":
+		return "missing"
+	case len(encoded) == expectedBytes*2:
+		return "hex"
+	case len(encoded) == expectedBytes*2+2 && (strings.HasPrefix(encoded, "0x") || strings.HasPrefix(encoded, "0X")):
+		return "hex-0x"
+	case strings.HasSuffix(encoded, "="):
+		return encoding + "-padded"
+	default:
+		return encoding
+	}
+}
+
 // If GET, we sign a visitor POD and redirect to add the POD via Zupass
 // If POST, we sign the given entries and return the given Zupass URL
 func handleZupass(w http.ResponseWriter, r *http.Request) {
@@ -132,9 +368,9 @@ func handleZupass(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		podInstance, err = pod.CreatePod(privateKey, req.Entries)
+		podInstance, err = pod.CreatePodFromJSON(privateKey, req.Entries)
 		if err != nil {
-			http.Error(w, "Error creating POD: "+err.Error(), http.StatusInternalServerError)
+			http.Error(w, "Error creating POD: "+err.Error(), createPodErrorStatus(err))
 			return
 		}
 
@@ -225,20 +461,55 @@ func initRedis() {
 	log.Printf("Connected to Redis at %s", redisURL)
 }
 
+// Load the signing key from the file named by PRIVATE_KEY_FILE if it's set, so
+// the key needn't appear in the environment, or else from PRIVATE_KEY.
+func loadPrivateKey() (string, error) {
+	envKey := os.Getenv("PRIVATE_KEY")
+	keyFile := os.Getenv("PRIVATE_KEY_FILE")
+	if keyFile == "" {
+		if envKey == "" {
+			return "", fmt.Errorf("missing PRIVATE_KEY or PRIVATE_KEY_FILE environment variable")
+		}
+		return envKey, nil
+	}
+
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read PRIVATE_KEY_FILE: %w", err)
+	}
+	fileKey := strings.TrimSpace(string(data))
+	if fileKey == "" {
+		return "", fmt.Errorf("PRIVATE_KEY_FILE %s is empty", keyFile)
+	}
+	if envKey != "" && envKey != fileKey {
+		return "", fmt.Errorf("PRIVATE_KEY and PRIVATE_KEY_FILE are both set to different keys")
+	}
+	return fileKey, nil
+}
+
 func main() {
 	_ = godotenv.Load()
 
-	if privateKey == "" {
-		log.Fatal("Missing PRIVATE_KEY environment variable.")
+	var err error
+	privateKey, err = loadPrivateKey()
+	if err != nil {
+		log.Fatal(err)
 	}
-	log.Println("Loaded PRIVATE_KEY...")
+	log.Println("Loaded private key...")
 
 	initRedis()
 
 	http.HandleFunc("/", handleRoot)
 	http.HandleFunc("/sign", handleSign)
 	http.HandleFunc("/verify", handleVerify)
+	http.HandleFunc("/challenge", handleChallenge)
+	http.HandleFunc("/verify-challenge", handleVerifyChallenge)
 	http.HandleFunc("/zupass", handleZupass)
+	http.HandleFunc("/version", handleVersion)
+	if os.Getenv("ENABLE_DEBUG_ENDPOINTS") == "true" {
+		log.Println("Debug endpoints enabled")
+		http.HandleFunc("/debug/pod", handleDebugPod)
+	}
 
 	log.Println("Starting server on port 8080")
 
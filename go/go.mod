@@ -5,6 +5,7 @@ go 1.22
 toolchain go1.23.5
 
 require (
+	github.com/fxamacker/cbor/v2 v2.9.4
 	github.com/go-test/deep v1.1.1
 	github.com/google/uuid v1.6.0
 	github.com/iden3/go-iden3-crypto/v2 v2.0.0
@@ -16,6 +17,7 @@ require (
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/dchest/blake512 v1.0.0 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/x448/float16 v0.8.4 // indirect
 	golang.org/x/crypto v0.32.0 // indirect
 	golang.org/x/sys v0.29.0 // indirect
 )
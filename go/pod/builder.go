@@ -0,0 +1,108 @@
+package pod
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Builds the entries of a POD one at a time with chainable methods, then signs
+// them.  Each value is checked as it's added, and any errors are remembered
+// until the POD is signed, so a whole POD can be built in one expression:
+//
+//	p, err := NewPodBuilder().AddString("name", "Alice").AddInt("age", big.NewInt(42)).Sign(privateKey)
+type PodBuilder struct {
+	entries PodEntries
+	errs    []error
+}
+
+// Create a builder with no entries.
+func NewPodBuilder() *PodBuilder {
+	return &PodBuilder{entries: PodEntries{}}
+}
+
+func (b *PodBuilder) add(name string, value PodValue, err error) *PodBuilder {
+	if err == nil {
+		err = CheckPodName(name)
+	}
+	if _, ok := b.entries[name]; ok && err == nil {
+		err = fmt.Errorf("duplicate entry name %q", name)
+	}
+	if err != nil {
+		b.errs = append(b.errs, fmt.Errorf("%s: %w", name, err))
+		return b
+	}
+	b.entries[name] = value
+	return b
+}
+
+// Add a string entry.
+func (b *PodBuilder) AddString(name string, val string) *PodBuilder {
+	value := NewPodStringValue(val)
+	return b.add(name, value, value.Check())
+}
+
+// Add an int entry.
+func (b *PodBuilder) AddInt(name string, val *big.Int) *PodBuilder {
+	value, err := NewPodIntValue(val)
+	return b.add(name, value, err)
+}
+
+// Add a boolean entry.
+func (b *PodBuilder) AddBool(name string, val bool) *PodBuilder {
+	return b.add(name, NewPodBooleanValue(val), nil)
+}
+
+// Add a bytes entry.
+func (b *PodBuilder) AddBytes(name string, val []byte) *PodBuilder {
+	value, err := NewPodBytesValue(val)
+	return b.add(name, value, err)
+}
+
+// Add a date entry.
+func (b *PodBuilder) AddDate(name string, val time.Time) *PodBuilder {
+	value, err := NewPodDateValue(val)
+	return b.add(name, value, err)
+}
+
+// Add a cryptographic entry.
+func (b *PodBuilder) AddCryptographic(name string, val *big.Int) *PodBuilder {
+	value, err := NewPodCryptographicValue(val)
+	return b.add(name, value, err)
+}
+
+// Add an eddsa_pubkey entry.
+func (b *PodBuilder) AddEdDSAPubkey(name string, val string) *PodBuilder {
+	value, err := NewPodEdDSAPubkeyValue(val)
+	return b.add(name, value, err)
+}
+
+// Add a null entry.
+func (b *PodBuilder) AddNull(name string) *PodBuilder {
+	return b.add(name, NewPodNullValue(), nil)
+}
+
+// Returns the errors from every entry which couldn't be added, in the order
+// they were added.
+func (b *PodBuilder) Errors() []error {
+	return b.errs
+}
+
+// Returns a copy of the entries added so far, or the first error from an entry
+// which couldn't be added.
+func (b *PodBuilder) Entries() (PodEntries, error) {
+	if len(b.errs) > 0 {
+		return nil, b.errs[0]
+	}
+	return b.entries.Clone(), nil
+}
+
+// Sign the entries added so far with the given private key, as CreatePod does.
+// Returns the first error from an entry which couldn't be added, if any.
+func (b *PodBuilder) Sign(privateKeyHex string) (*Pod, error) {
+	entries, err := b.Entries()
+	if err != nil {
+		return nil, err
+	}
+	return CreatePod(privateKeyHex, entries)
+}
@@ -0,0 +1,72 @@
+package pod
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPodBuilder(t *testing.T) {
+	const privateKey = "0001020304050607080900010203040506070809000102030405060708090001"
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	pod, err := NewPodBuilder().
+		AddString("s", "hello").
+		AddInt("i", big.NewInt(42)).
+		AddBool("b", true).
+		AddBytes("y", []byte{1, 2, 3}).
+		AddDate("d", date).
+		AddCryptographic("c", big.NewInt(7)).
+		AddEdDSAPubkey("pk", "xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4").
+		AddNull("n").
+		Sign(privateKey)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	expected, err := CreatePod(privateKey, PodEntries{
+		"s":  NewPodStringValue("hello"),
+		"i":  PodValue{ValueType: PodIntValue, BigVal: big.NewInt(42)},
+		"b":  NewPodBooleanValue(true),
+		"y":  PodValue{ValueType: PodBytesValue, BytesVal: []byte{1, 2, 3}},
+		"d":  PodValue{ValueType: PodDateValue, TimeVal: date},
+		"c":  PodValue{ValueType: PodCryptographicValue, BigVal: big.NewInt(7)},
+		"pk": PodValue{ValueType: PodEdDSAPubkeyValue, StringVal: "xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4"},
+		"n":  NewPodNullValue(),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	if pod.Signature != expected.Signature {
+		t.Fatalf("signature %s differs from %s", pod.Signature, expected.Signature)
+	}
+}
+
+func TestPodBuilderErrors(t *testing.T) {
+	builder := NewPodBuilder().
+		AddString("ok", "fine").
+		AddInt("big", new(big.Int).Lsh(big.NewInt(1), 64)).
+		AddBool("bad name", true).
+		AddBytes("nil", nil).
+		AddString("ok", "again")
+	errs := builder.Errors()
+	if len(errs) != 4 {
+		t.Fatalf("expected 4 errors, got %v", errs)
+	}
+	for i, prefix := range []string{"big:", "bad name:", "nil:", "ok: duplicate"} {
+		if !strings.HasPrefix(errs[i].Error(), prefix) {
+			t.Fatalf("expected error %d to start with %q, got %v", i, prefix, errs[i])
+		}
+	}
+	if _, err := builder.Sign("0001020304050607080900010203040506070809000102030405060708090001"); err != errs[0] {
+		t.Fatalf("expected first error from Sign, got %v", err)
+	}
+	if _, err := builder.Entries(); err != errs[0] {
+		t.Fatalf("expected first error from Entries, got %v", err)
+	}
+
+	if _, err := NewPodBuilder().AddNull("n").Sign("bad"); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Fatalf("expected invalid private key error, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package pod
+
+import (
+	"fmt"
+)
+
+// Name of the entry recording the content ID of the source POD with the
+// given index in an aggregate POD.
+func aggregateSourceEntryName(srcIndex int) string {
+	return fmt.Sprintf("_src_%d", srcIndex)
+}
+
+// Combine the entries of several PODs into one new POD signed by s, recording
+// where they came from.  Each source POD must verify.  The entries of each
+// source are renamed by prepending prefix(srcIndex), which must keep names
+// legal and unique; if prefix is nil, "src<N>_" is used.  The content ID of
+// each source is added as a cryptographic entry named "_src_<N>".
+func AggregatePods(s *Signer, pods []*Pod, prefix func(srcIndex int) string) (*Pod, error) {
+	if prefix == nil {
+		prefix = func(srcIndex int) string { return fmt.Sprintf("src%d_", srcIndex) }
+	}
+
+	combined := PodEntries{}
+	addEntry := func(name string, value PodValue) error {
+		if err := CheckPodName(name); err != nil {
+			return err
+		}
+		if _, exists := combined[name]; exists {
+			return fmt.Errorf("aggregate POD entry name %q is not unique", name)
+		}
+		combined[name] = value
+		return nil
+	}
+
+	for i, p := range pods {
+		ok, err := p.Verify()
+		if err != nil {
+			return nil, fmt.Errorf("failed to verify source POD %d: %w", i, err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("source POD %d has an invalid signature", i)
+		}
+
+		namePrefix := prefix(i)
+		for name, value := range p.Entries {
+			if err := addEntry(namePrefix+name, value); err != nil {
+				return nil, fmt.Errorf("failed to add entry from source POD %d: %w", i, err)
+			}
+		}
+
+		contentID, err := ComputeContentID(p.Entries)
+		if err != nil {
+			return nil, fmt.Errorf("failed computing content ID of source POD %d: %w", i, err)
+		}
+		contentIDValue, err := NewPodCryptographicValue(contentID)
+		if err != nil {
+			return nil, err
+		}
+		if err := addEntry(aggregateSourceEntryName(i), contentIDValue); err != nil {
+			return nil, fmt.Errorf("failed to add provenance of source POD %d: %w", i, err)
+		}
+	}
+
+	return s.Sign(combined)
+}
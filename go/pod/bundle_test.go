@@ -0,0 +1,61 @@
+package pod
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestAggregatePods(t *testing.T) {
+	signer, err := NewSigner("0001020304050607080900010203040506070809000102030405060708090001")
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	first, err := signer.Sign(PodEntries{"name": NewPodStringValue("Alice")})
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	second, err := signer.Sign(PodEntries{
+		"name":  NewPodStringValue("Bob"),
+		"count": PodValue{ValueType: PodIntValue, BigVal: big.NewInt(2)},
+	})
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	aggregate, err := AggregatePods(signer, []*Pod{first, second}, nil)
+	if err != nil {
+		t.Fatalf("AggregatePods failed: %v", err)
+	}
+	ok, err := aggregate.Verify()
+	if err != nil || !ok {
+		t.Fatalf("aggregate POD failed to verify: %v", err)
+	}
+	if len(aggregate.Entries) != 5 {
+		t.Fatalf("unexpected aggregate entries: %v", aggregate.Entries)
+	}
+	if aggregate.Entries["src0_name"].StringVal != "Alice" || aggregate.Entries["src1_name"].StringVal != "Bob" {
+		t.Fatalf("unexpected aggregate entries: %v", aggregate.Entries)
+	}
+	for i, source := range []*Pod{first, second} {
+		contentID, err := ComputeContentID(source.Entries)
+		if err != nil {
+			t.Fatalf("ComputeContentID failed: %v", err)
+		}
+		provenance := aggregate.Entries[fmt.Sprintf("_src_%d", i)]
+		if provenance.ValueType != PodCryptographicValue || provenance.BigVal.Cmp(contentID) != 0 {
+			t.Fatalf("unexpected provenance for source %d: %v", i, provenance)
+		}
+	}
+
+	// A prefix which doesn't separate the sources causes a collision.
+	if _, err := AggregatePods(signer, []*Pod{first, second}, func(int) string { return "" }); err == nil {
+		t.Fatalf("expected colliding entry names to fail")
+	}
+
+	// Sources must verify.
+	second.Entries["name"] = NewPodStringValue("Mallory")
+	if _, err := AggregatePods(signer, []*Pod{first, second}, nil); err == nil {
+		t.Fatalf("expected invalid source POD to fail")
+	}
+}
@@ -0,0 +1,111 @@
+package pod
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+)
+
+// A cache of parsed and verified PODs, keyed by a hash of their serialized
+// JSON, so that identical payloads are only parsed and verified once.  Valid
+// and invalid results are kept in separate LRU caches with their own
+// capacities, so a flood of bad payloads can't evict good ones.  Safe for
+// concurrent use.
+//
+// PODs returned from the cache are shared between callers, and must not be
+// modified.
+type PodCache struct {
+	mutex    sync.Mutex
+	valid    *lruCache
+	invalid  *lruCache
+	verifier func(data []byte) VerifyResult
+}
+
+// Create a cache holding up to validCapacity verified PODs, and up to
+// invalidCapacity failed results.  A capacity of 0 disables caching of that
+// kind of result.
+func NewPodCache(validCapacity int, invalidCapacity int) *PodCache {
+	return &PodCache{
+		valid:    newLRUCache(validCapacity),
+		invalid:  newLRUCache(invalidCapacity),
+		verifier: parseAndVerify,
+	}
+}
+
+// Parse and verify a POD from JSON, or return the cached result if the same
+// bytes were seen before.  Returns the POD and true if it's valid.  A POD
+// which parses but doesn't verify is returned with false, and a POD which
+// can't be parsed or checked is returned with an error.
+func (c *PodCache) GetOrVerify(data []byte) (*Pod, bool, error) {
+	key := sha256.Sum256(data)
+
+	c.mutex.Lock()
+	result, ok := c.valid.get(key)
+	if !ok {
+		result, ok = c.invalid.get(key)
+	}
+	c.mutex.Unlock()
+	if ok {
+		return result.Pod, result.Valid, result.Err
+	}
+
+	// Verify without holding the lock, since it's slow.  Concurrent callers
+	// with the same bytes may both verify, but will get the same result.
+	result = c.verifier(data)
+
+	c.mutex.Lock()
+	if result.Valid {
+		c.valid.add(key, result)
+	} else {
+		c.invalid.add(key, result)
+	}
+	c.mutex.Unlock()
+	return result.Pod, result.Valid, result.Err
+}
+
+// A fixed-capacity map which evicts its least recently used entry.  Not safe
+// for concurrent use.
+type lruCache struct {
+	capacity int
+	order    *list.List
+	items    map[[32]byte]*list.Element
+}
+
+type lruEntry struct {
+	key    [32]byte
+	result VerifyResult
+}
+
+func newLRUCache(capacity int) *lruCache {
+	return &lruCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[[32]byte]*list.Element),
+	}
+}
+
+func (l *lruCache) get(key [32]byte) (VerifyResult, bool) {
+	element, ok := l.items[key]
+	if !ok {
+		return VerifyResult{}, false
+	}
+	l.order.MoveToFront(element)
+	return element.Value.(*lruEntry).result, true
+}
+
+func (l *lruCache) add(key [32]byte, result VerifyResult) {
+	if l.capacity <= 0 {
+		return
+	}
+	if element, ok := l.items[key]; ok {
+		element.Value.(*lruEntry).result = result
+		l.order.MoveToFront(element)
+		return
+	}
+	l.items[key] = l.order.PushFront(&lruEntry{key: key, result: result})
+	if l.order.Len() > l.capacity {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(*lruEntry).key)
+	}
+}
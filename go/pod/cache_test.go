@@ -0,0 +1,103 @@
+package pod
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+func TestPodCache(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	validJSON, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatalf("Failed to marshal pod to JSON: %v", err)
+	}
+	pod.Entries["message"] = NewPodStringValue("tampered")
+	tamperedJSON, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatalf("Failed to marshal pod to JSON: %v", err)
+	}
+	malformedJSON := []byte("{not json")
+
+	cache := NewPodCache(1, 2)
+	verifications := 0
+	cache.verifier = func(data []byte) VerifyResult {
+		verifications++
+		return parseAndVerify(data)
+	}
+
+	for i := 0; i < 2; i++ {
+		cached, ok, err := cache.GetOrVerify(validJSON)
+		if err != nil || !ok || cached.Entries["message"].StringVal != "hello" {
+			t.Fatalf("expected valid POD, got %v %v", ok, err)
+		}
+		if _, ok, err := cache.GetOrVerify(tamperedJSON); err != nil || ok {
+			t.Fatalf("expected tampered POD to fail: %v", err)
+		}
+		if _, _, err := cache.GetOrVerify(malformedJSON); err == nil {
+			t.Fatalf("expected malformed POD to fail")
+		}
+	}
+	if verifications != 3 {
+		t.Fatalf("expected 3 verifications, got %d", verifications)
+	}
+
+	// Filling the valid cache evicts the older valid POD, but not the invalid
+	// results.
+	otherPod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("other"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	otherJSON, err := json.Marshal(otherPod)
+	if err != nil {
+		t.Fatalf("Failed to marshal pod to JSON: %v", err)
+	}
+	if _, ok, err := cache.GetOrVerify(otherJSON); err != nil || !ok {
+		t.Fatalf("expected valid POD: %v", err)
+	}
+	if _, ok, err := cache.GetOrVerify(tamperedJSON); err != nil || ok {
+		t.Fatalf("expected tampered POD to fail: %v", err)
+	}
+	if verifications != 4 {
+		t.Fatalf("expected 4 verifications, got %d", verifications)
+	}
+	if _, ok, err := cache.GetOrVerify(validJSON); err != nil || !ok {
+		t.Fatalf("expected valid POD: %v", err)
+	}
+	if verifications != 5 {
+		t.Fatalf("expected evicted POD to be verified again, got %d verifications", verifications)
+	}
+}
+
+func TestPodCacheConcurrent(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	validJSON, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatalf("Failed to marshal pod to JSON: %v", err)
+	}
+
+	cache := NewPodCache(8, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok, err := cache.GetOrVerify(validJSON); err != nil || !ok {
+				t.Errorf("expected valid POD: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,196 @@
+package pod
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// CBOR encoding of a POD, as a map with the same field names as JSON.  The
+// signature and public key are encoded as byte strings.
+type cborPod struct {
+	Entries         map[string]cborValue `cbor:"entries"`
+	Signature       []byte               `cbor:"signature"`
+	SignerPublicKey []byte               `cbor:"signerPublicKey"`
+}
+
+// CBOR encoding of a value, as a two-element array of its type name and its
+// value.  Numbers are CBOR integers or bignums, dates are milliseconds since
+// epoch, and public keys are 32-byte strings.
+type cborValue struct {
+	_     struct{} `cbor:",toarray"`
+	Type  PodValueType
+	Value interface{}
+}
+
+// Deterministic encoding options, with map keys sorted and integers in their
+// shortest form, so that the same POD always has the same encoding.
+var cborEncMode = func() cbor.EncMode {
+	mode, err := cbor.CoreDetEncOptions().EncMode()
+	if err != nil {
+		panic(fmt.Sprintf("invalid CBOR encoding options: %v", err))
+	}
+	return mode
+}()
+
+var cborDecMode = func() cbor.DecMode {
+	mode, err := cbor.DecOptions{
+		DupMapKey: cbor.DupMapKeyEnforcedAPF,
+		BigIntDec: cbor.BigIntDecodePointer,
+	}.DecMode()
+	if err != nil {
+		panic(fmt.Sprintf("invalid CBOR decoding options: %v", err))
+	}
+	return mode
+}()
+
+// Serialize this POD as deterministic CBOR.  The signature and public key are
+// stored as bytes, so they're decoded in canonical Base64 regardless of how
+// they were encoded.
+func (p *Pod) MarshalCBOR() ([]byte, error) {
+	if err := p.CheckFormat(); err != nil {
+		return nil, err
+	}
+	signature, err := DecodeBytes(p.Signature, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signature: %w", err)
+	}
+	publicKey, err := DecodeBytes(p.SignerPublicKey, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode signer public key: %w", err)
+	}
+
+	entries := make(map[string]cborValue, len(p.Entries))
+	for name, value := range p.Entries {
+		encoded, err := value.toCBOR()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		entries[name] = encoded
+	}
+	return cborEncMode.Marshal(cborPod{
+		Entries:         entries,
+		Signature:       signature,
+		SignerPublicKey: publicKey,
+	})
+}
+
+// Parse a POD from CBOR produced by MarshalCBOR.
+func (p *Pod) UnmarshalCBOR(data []byte) error {
+	var decoded cborPod
+	if err := cborDecMode.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Entries == nil {
+		return fmt.Errorf("unmarshalled POD entries are nil")
+	}
+	entries := make(PodEntries, len(decoded.Entries))
+	for name, encoded := range decoded.Entries {
+		value, err := encoded.toPodValue()
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		entries[name] = value
+	}
+	if err := entries.Check(); err != nil {
+		return err
+	}
+
+	if len(decoded.Signature) != 64 {
+		return fmt.Errorf("signature must be 64 bytes, got %d", len(decoded.Signature))
+	}
+	if len(decoded.SignerPublicKey) != 32 {
+		return fmt.Errorf("signer public key must be 32 bytes, got %d", len(decoded.SignerPublicKey))
+	}
+	*p = Pod{
+		Entries:         entries,
+		Signature:       noPadB64.EncodeToString(decoded.Signature),
+		SignerPublicKey: noPadB64.EncodeToString(decoded.SignerPublicKey),
+	}
+	return nil
+}
+
+func (p PodValue) toCBOR() (cborValue, error) {
+	if err := p.Check(); err != nil {
+		return cborValue{}, err
+	}
+	switch p.ValueType {
+	case PodNullValue:
+		return cborValue{Type: p.ValueType}, nil
+	case PodStringValue:
+		return cborValue{Type: p.ValueType, Value: p.StringVal}, nil
+	case PodBooleanValue:
+		return cborValue{Type: p.ValueType, Value: p.BoolVal}, nil
+	case PodIntValue, PodCryptographicValue:
+		return cborValue{Type: p.ValueType, Value: p.BigVal}, nil
+	case PodBytesValue:
+		return cborValue{Type: p.ValueType, Value: p.BytesVal}, nil
+	case PodDateValue:
+		return cborValue{Type: p.ValueType, Value: p.TimeVal.UnixMilli()}, nil
+	case PodEdDSAPubkeyValue:
+		publicKey, err := DecodeBytes(p.StringVal, 32)
+		if err != nil {
+			return cborValue{}, fmt.Errorf("failed to decode public key: %w", err)
+		}
+		return cborValue{Type: p.ValueType, Value: publicKey}, nil
+	default:
+		return cborValue{}, fmt.Errorf("unknown PodValueType %q", p.ValueType)
+	}
+}
+
+func (c cborValue) toPodValue() (PodValue, error) {
+	value := PodValue{ValueType: c.Type}
+	var ok bool
+	switch c.Type {
+	case PodNullValue:
+		ok = c.Value == nil
+	case PodStringValue:
+		value.StringVal, ok = c.Value.(string)
+	case PodBooleanValue:
+		value.BoolVal, ok = c.Value.(bool)
+	case PodIntValue, PodCryptographicValue:
+		value.BigVal, ok = cborBigInt(c.Value)
+	case PodBytesValue:
+		value.BytesVal, ok = c.Value.([]byte)
+	case PodDateValue:
+		var millis *big.Int
+		if millis, ok = cborBigInt(c.Value); ok {
+			if !millis.IsInt64() {
+				return PodValue{}, fmt.Errorf("date %v ms out of range", millis)
+			}
+			value.TimeVal = time.UnixMilli(millis.Int64()).UTC()
+		}
+	case PodEdDSAPubkeyValue:
+		var publicKey []byte
+		if publicKey, ok = c.Value.([]byte); ok {
+			if len(publicKey) != 32 {
+				return PodValue{}, fmt.Errorf("public key must be 32 bytes, got %d", len(publicKey))
+			}
+			value.StringVal = noPadB64.EncodeToString(publicKey)
+		}
+	default:
+		return PodValue{}, fmt.Errorf("unknown PodValueType %q", c.Type)
+	}
+	if !ok {
+		return PodValue{}, fmt.Errorf("invalid CBOR encoding for %s, got %T", c.Type, c.Value)
+	}
+	return value, value.Check()
+}
+
+// Converts a decoded CBOR integer or bignum to a big.Int.
+func cborBigInt(v interface{}) (*big.Int, bool) {
+	switch n := v.(type) {
+	case int64:
+		return big.NewInt(n), true
+	case uint64:
+		return new(big.Int).SetUint64(n), true
+	case *big.Int:
+		return n, n != nil
+	case big.Int:
+		return &n, true
+	default:
+		return nil, false
+	}
+}
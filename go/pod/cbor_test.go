@@ -0,0 +1,84 @@
+package pod
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+func TestCBORRoundTrip(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"A": PodValue{ValueType: PodIntValue, BigVal: big.NewInt(9007199254740992)},
+		"G": PodValue{ValueType: PodIntValue, BigVal: PodIntMin()},
+		"S": PodValue{ValueType: PodStringValue, StringVal: "foobar"},
+		"C": PodValue{ValueType: PodBooleanValue, BoolVal: true},
+		"N": PodValue{ValueType: PodNullValue},
+		"J": PodValue{ValueType: PodBytesValue, BytesVal: []byte{0x01, 0x02, 0x03}},
+		"E": PodValue{ValueType: PodBytesValue, BytesVal: []byte{}},
+		"K": PodValue{ValueType: PodCryptographicValue, BigVal: PodCryptographicMax()},
+		"D": PodValue{ValueType: PodDateValue, TimeVal: time.Date(2025, 1, 1, 0, 0, 0, 123e6, time.UTC)},
+		"P": PodValue{ValueType: PodEdDSAPubkeyValue, StringVal: "xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4"},
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+
+	encoded, err := cbor.Marshal(pod)
+	if err != nil {
+		t.Fatalf("failed to marshal CBOR: %v", err)
+	}
+	var decoded Pod
+	if err := cbor.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal CBOR: %v", err)
+	}
+	if len(decoded.Entries) != len(pod.Entries) {
+		t.Fatalf("expected %d entries, got %d", len(pod.Entries), len(decoded.Entries))
+	}
+	for name, value := range pod.Entries {
+		if !decoded.Entries[name].Equal(value) {
+			t.Fatalf("entry %s changed in round trip: %v became %v", name, value, decoded.Entries[name])
+		}
+	}
+	if decoded.Signature != pod.Signature || decoded.SignerPublicKey != pod.SignerPublicKey {
+		t.Fatalf("signature or public key changed in round trip")
+	}
+	ok, err := decoded.Verify()
+	if err != nil || !ok {
+		t.Fatalf("expected decoded POD to verify: %v", err)
+	}
+
+	// The encoding is deterministic, regardless of map iteration order or the
+	// encoding of the signature.
+	for i := 0; i < 10; i++ {
+		again, err := decoded.MarshalCBOR()
+		if err != nil {
+			t.Fatalf("failed to marshal CBOR: %v", err)
+		}
+		if !bytes.Equal(again, encoded) {
+			t.Fatalf("CBOR encoding is not deterministic")
+		}
+	}
+}
+
+func TestBadCBORPod(t *testing.T) {
+	var pod Pod
+	for _, data := range []interface{}{
+		map[string]interface{}{},
+		map[string]interface{}{"entries": map[string]interface{}{}, "signature": make([]byte, 63), "signerPublicKey": make([]byte, 32)},
+		map[string]interface{}{"entries": map[string]interface{}{"a": []interface{}{"int", "1"}}, "signature": make([]byte, 64), "signerPublicKey": make([]byte, 32)},
+		map[string]interface{}{"entries": map[string]interface{}{"a": []interface{}{"cryptographic", -1}}, "signature": make([]byte, 64), "signerPublicKey": make([]byte, 32)},
+		map[string]interface{}{"entries": map[string]interface{}{"a": []interface{}{"unknown", 1}}, "signature": make([]byte, 64), "signerPublicKey": make([]byte, 32)},
+		map[string]interface{}{"entries": map[string]interface{}{"bad name": []interface{}{"null", nil}}, "signature": make([]byte, 64), "signerPublicKey": make([]byte, 32)},
+	} {
+		encoded, err := cbor.Marshal(data)
+		if err != nil {
+			t.Fatalf("failed to marshal CBOR: %v", err)
+		}
+		if err := cbor.Unmarshal(encoded, &pod); err == nil {
+			t.Fatalf("expected %v to fail to unmarshal", data)
+		}
+	}
+}
@@ -1,7 +1,11 @@
 package pod
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"math/big"
+	"time"
 
 	"github.com/iden3/go-iden3-crypto/v2/babyjub"
 )
@@ -9,6 +13,14 @@ import (
 // A reusable POD signer which can create multiple PODs with the same key.
 type Signer struct {
 	privateKey babyjub.PrivateKey
+
+	// If non-zero, the maximum number of entries this signer will sign, to
+	// bound the work of signing entries which come from untrusted input.
+	MaxEntries int
+
+	// If non-zero, the maximum total size in bytes of the names, strings, and
+	// bytes values this signer will hash when signing.
+	MaxHashedBytes int
 }
 
 // Create a new Signer with the given private key.
@@ -21,13 +33,152 @@ func NewSigner(privateKeyHex string) (*Signer, error) {
 	return &Signer{privateKey: privateKey}, nil
 }
 
+// Create a new Signer with a random private key drawn from RandSource.  Any 32
+// bytes are a valid EdDSA private key, since the signing scalar is derived by
+// hashing them.
+func GenerateSigner() (*Signer, error) {
+	privateKeyBytes, err := randomBytes(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate private key: %w", err)
+	}
+	return &Signer{privateKey: babyjub.PrivateKey(privateKeyBytes)}, nil
+}
+
+// Returns the private key of this signer as hex, which can be passed to
+// NewSigner to recreate it.
+func (s *Signer) PrivateKeyHex() string {
+	return hex.EncodeToString(s.privateKey[:])
+}
+
 // Create and sign a new POD.  This involves hashing all the given entries
 // to generate a Content ID, then signing that content ID with the given
 // private key.
 func (s *Signer) Sign(entries PodEntries) (*Pod, error) {
+	if err := s.checkLimits(entries); err != nil {
+		return nil, err
+	}
 	return signPod(s.privateKey, entries)
 }
 
+func (s *Signer) checkLimits(entries PodEntries) error {
+	if s.MaxEntries > 0 {
+		if err := entries.CheckMaxEntries(s.MaxEntries); err != nil {
+			return err
+		}
+	}
+	if s.MaxHashedBytes > 0 {
+		hashedBytes := 0
+		for name, value := range entries {
+			hashedBytes += len(name)
+			switch value.ValueType {
+			case PodStringValue:
+				hashedBytes += len(value.StringVal)
+			case PodBytesValue:
+				hashedBytes += len(value.BytesVal)
+			}
+		}
+		if hashedBytes > s.MaxHashedBytes {
+			return fmt.Errorf("POD entries contain %d bytes to hash, more than the maximum of %d", hashedBytes, s.MaxHashedBytes)
+		}
+	}
+	return nil
+}
+
+// A signed POD wrapped with the metadata needed to audit its creation.
+//
+// Only the POD itself is signed.  ContentID and SignerPublicKey are checked
+// against the POD when unmarshalling, but SignedAt and LibraryVersion aren't
+// covered by any signature, so anyone holding the bundle can change them.
+// Treat them as unauthenticated hints, not as attested facts.
+type AttestationBundle struct {
+	Pod             *Pod
+	ContentID       string // 0x-prefixed hex
+	SignerPublicKey string
+	SignedAt        time.Time
+	LibraryVersion  string
+}
+
+// Create and sign a new POD, returning it in an AttestationBundle along with
+// its content ID, signer, signing time, and the version of this library.
+// The signing time and library version are not signed; see AttestationBundle.
+func (s *Signer) SignBundle(entries PodEntries) (*AttestationBundle, error) {
+	if err := s.checkLimits(entries); err != nil {
+		return nil, err
+	}
+	contentID, err := ComputeContentID(entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed computing content ID: %w", err)
+	}
+	pod, err := signContentID(s.privateKey, entries, contentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AttestationBundle{
+		Pod:             pod,
+		ContentID:       formatContentID(contentID),
+		SignerPublicKey: pod.SignerPublicKey,
+		SignedAt:        time.Now().Truncate(time.Millisecond).UTC(),
+		LibraryVersion:  Version(),
+	}, nil
+}
+
+type attestationBundleJSON struct {
+	Pod             *Pod   `json:"pod"`
+	ContentID       string `json:"contentId"`
+	SignerPublicKey string `json:"signerPublicKey"`
+	SignedAt        string `json:"signedAt"`
+	LibraryVersion  string `json:"libraryVersion"`
+}
+
+// Marshal this bundle to JSON, with the POD in its usual terse format and the
+// signing time in the same millisecond-precision UTC format as POD dates.
+func (b AttestationBundle) MarshalJSON() ([]byte, error) {
+	return json.Marshal(attestationBundleJSON{
+		Pod:             b.Pod,
+		ContentID:       b.ContentID,
+		SignerPublicKey: b.SignerPublicKey,
+		SignedAt:        b.SignedAt.UTC().Format("2006-01-02T15:04:05.000Z"),
+		LibraryVersion:  b.LibraryVersion,
+	})
+}
+
+// Parse a bundle from JSON.  The metadata is checked for consistency with the
+// wrapped POD, but the POD's signature is not verified.
+func (b *AttestationBundle) UnmarshalJSON(data []byte) error {
+	var deserialized attestationBundleJSON
+	if err := json.Unmarshal(data, &deserialized); err != nil {
+		return err
+	}
+	if deserialized.Pod == nil {
+		return fmt.Errorf("attestation bundle is missing its POD")
+	}
+	signedAt, err := time.Parse(time.RFC3339, deserialized.SignedAt)
+	if err != nil {
+		return fmt.Errorf("invalid attestation bundle signing time: %w", err)
+	}
+
+	contentID, err := ComputeContentID(deserialized.Pod.Entries)
+	if err != nil {
+		return fmt.Errorf("failed computing content ID: %w", err)
+	}
+	if formatContentID(contentID) != deserialized.ContentID {
+		return fmt.Errorf("attestation bundle content ID %s does not match POD", deserialized.ContentID)
+	}
+	if deserialized.SignerPublicKey != deserialized.Pod.SignerPublicKey {
+		return fmt.Errorf("attestation bundle signer %s does not match POD", deserialized.SignerPublicKey)
+	}
+
+	*b = AttestationBundle{
+		Pod:             deserialized.Pod,
+		ContentID:       deserialized.ContentID,
+		SignerPublicKey: deserialized.SignerPublicKey,
+		SignedAt:        signedAt.UTC(),
+		LibraryVersion:  deserialized.LibraryVersion,
+	}
+	return nil
+}
+
 // Create and sign a new POD.  This involves hashing all the given entries
 // to generate a Content ID, then signing that content ID with the given
 // private key.
@@ -40,12 +191,61 @@ func CreatePod(privateKeyHex string, entries PodEntries) (*Pod, error) {
 	return signPod(privateKey, entries)
 }
 
+// Create and sign a new POD from entries in POD's JSON format, as accepted by
+// PodEntries.UnmarshalJSON.
+func CreatePodFromJSON(privateKeyHex string, entriesJSON []byte) (*Pod, error) {
+	var entries PodEntries
+	if err := json.Unmarshal(entriesJSON, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse entries: %w", err)
+	}
+	return CreatePod(privateKeyHex, entries)
+}
+
+// Assemble a POD from entries and a signature made elsewhere, such as in an
+// HSM which signed the content ID without exposing its private key.  The
+// signature must be 64 bytes and the public key 32 bytes, in their compressed
+// forms.  The assembled POD is verified before it's returned, so a signature
+// over the wrong content ID is an error.
+func AssemblePod(entries PodEntries, signature []byte, publicKey []byte) (*Pod, error) {
+	if len(signature) != 64 {
+		return nil, fmt.Errorf("%w: must be 64 bytes, got %d", ErrMalformedSignature, len(signature))
+	}
+	if len(publicKey) != 32 {
+		return nil, fmt.Errorf("%w: must be 32 bytes, got %d", ErrMalformedPublicKey, len(publicKey))
+	}
+	pod := &Pod{
+		Entries:         entries,
+		Signature:       noPadB64.EncodeToString(signature),
+		SignerPublicKey: noPadB64.EncodeToString(publicKey),
+	}
+	ok, err := pod.Verify()
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify assembled POD: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: not valid for these entries and public key", ErrSignatureMismatch)
+	}
+	return pod, nil
+}
+
+// Returns the public key matching a private key given as hex or Base64, as
+// accepted by CreatePod.  The public key is compressed and encoded in
+// unpadded Base64, as in a POD's SignerPublicKey.
+func EdDSAPublicKeyFromPrivate(encodedPrivateKey string) (string, error) {
+	privateKey, err := parsePrivateKey(encodedPrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse private key: %w", err)
+	}
+	publicKeyBytes := privateKey.Public().Compress()
+	return noPadB64.EncodeToString(publicKeyBytes[:]), nil
+}
+
 func parsePrivateKey(encodedPrivateKey string) (babyjub.PrivateKey, error) {
 	var privateKey babyjub.PrivateKey
 
 	privateKeyBytes, err := DecodeBytes(encodedPrivateKey, 32)
-	if err != nil || len(privateKeyBytes) != 32 {
-		return privateKey, fmt.Errorf("failed to parse private key: must be 32-byte hex or base64 string: %w", err)
+	if err != nil {
+		return privateKey, fmt.Errorf("%w: %w", ErrInvalidPrivateKey, err)
 	}
 
 	privateKey = babyjub.PrivateKey(privateKeyBytes)
@@ -54,10 +254,15 @@ func parsePrivateKey(encodedPrivateKey string) (babyjub.PrivateKey, error) {
 }
 
 func signPod(privateKey babyjub.PrivateKey, entries PodEntries) (*Pod, error) {
-	contentID, err := computeContentID(entries)
+	contentID, err := ComputeContentID(entries)
 	if err != nil {
 		return nil, fmt.Errorf("failed computing content ID: %w", err)
 	}
+	return signContentID(privateKey, entries, contentID)
+}
+
+// Sign an already computed content ID and build the POD for the given entries.
+func signContentID(privateKey babyjub.PrivateKey, entries PodEntries, contentID *big.Int) (*Pod, error) {
 	sig, err := privateKey.SignPoseidon(contentID)
 	if err != nil {
 		return nil, fmt.Errorf("failed signing content ID: %w", err)
@@ -1,37 +1,60 @@
 package pod
 
 import (
+	"crypto/rand"
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"regexp"
-	"sort"
+	"runtime"
+	"sync"
 
 	"github.com/iden3/go-iden3-crypto/v2/constants"
 	"github.com/iden3/go-iden3-crypto/v2/poseidon"
+	"github.com/iden3/go-iden3-crypto/v2/utils"
 )
 
 // Private keys are 32 bytes (any arbitrary bytes), represented as Base64 or
-// hexadecimal
+// hexadecimal.  Base64 may use the standard or URL-safe alphabet, and hex may
+// have a 0x prefix.
 //
 // This regex matches any supported format, with match groups usable to
 // determine the format, in the order above.
-var PrivateKeyRegex = regexp.MustCompile(`^(?:([A-Za-z0-9+/]{43}=?)|([0-9A-Fa-f]{64}))$`)
+var PrivateKeyRegex = regexp.MustCompile(`^(?:([A-Za-z0-9+/_-]{43}=?)|(?:0[xX])?([0-9A-Fa-f]{64}))$`)
 
 // Public keys are 32 bytes (a packed elliptic curve point), represented as
-// Base64 or hexadecimal.  Base64 padding is optional.
+// Base64 or hexadecimal.  Base64 padding is optional, and it may use the
+// standard or URL-safe alphabet.  Hex may have a 0x prefix.
 //
 // This regex matches any supported format, with match groups usable to
 // determine the format, in the order above.
-var PublicKeyRegex = regexp.MustCompile(`^(?:([A-Za-z0-9+/]{43}=?)|([0-9A-Fa-f]{64}))$`)
+var PublicKeyRegex = regexp.MustCompile(`^(?:([A-Za-z0-9+/_-]{43}=?)|(?:0[xX])?([0-9A-Fa-f]{64}))$`)
 
 // Signatures are 64 bytes (one packed elliptic curve point, one scalar),
-// represented as Base64 or hexadecimal.  Base64 padding is optional.
+// represented as Base64 or hexadecimal.  Base64 padding is optional, and it may
+// use the standard or URL-safe alphabet.  Hex may have a 0x prefix.
 //
 // This regex matches any supported format, with match groups usable to
 // determine the format, in the order above.
-var SignatureRegex = regexp.MustCompile(`^(?:([A-Za-z0-9+/]{86}(?:==)?)|([0-9A-Fa-f]{128}))$`)
+var SignatureRegex = regexp.MustCompile(`^(?:([A-Za-z0-9+/_-]{86}(?:==)?)|(?:0[xX])?([0-9A-Fa-f]{128}))$`)
+
+// Source of randomness for all functions in this package which need it, such
+// as key generation.  Defaults to crypto/rand.Reader.  It can be replaced with
+// a deterministic source in tests, or a hardware RNG in production.  Replacing
+// it with a predictable or low-entropy source compromises the security of any
+// keys or secrets generated from it.
+var RandSource io.Reader = rand.Reader
+
+// Reads n random bytes from RandSource.
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(RandSource, b); err != nil {
+		return nil, fmt.Errorf("failed to read randomness: %w", err)
+	}
+	return b, nil
+}
 
 func hashString(s string) *big.Int {
 	return hashBytes([]byte(s))
@@ -41,9 +64,24 @@ func hashString(s string) *big.Int {
 // the first 31 bytes of that digest as a big-endian integer.
 func hashBytes(data []byte) *big.Int {
 	hash := sha256.Sum256(data)
-	first31 := hash[:31]
-	x := new(big.Int).SetBytes(first31)
-	return x
+	return truncateDigest(hash[:])
+}
+
+// Hashes the bytes read from r exactly as a bytes POD value with the same
+// content would be hashed, but streaming the input rather than holding it all
+// in memory.
+func HashBytesReader(r io.Reader) (*big.Int, error) {
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, r); err != nil {
+		return nil, fmt.Errorf("failed to read bytes to hash: %w", err)
+	}
+	return truncateDigest(hasher.Sum(nil)), nil
+}
+
+// Interprets the first 31 bytes of a SHA-256 digest as a big-endian integer,
+// so that it fits in the field.
+func truncateDigest(digest []byte) *big.Int {
+	return new(big.Int).SetBytes(digest[:31])
 }
 
 func fieldSafeInt64(val int64) *big.Int {
@@ -52,51 +90,169 @@ func fieldSafeInt64(val int64) *big.Int {
 	return x
 }
 
-func computeContentID(data PodEntries) (*big.Int, error) {
-	if err := data.Check(); err != nil {
+// Arity of the lean incremental Merkle tree used to compute content IDs.
+// This is the only arity compatible with other POD implementations.
+const DefaultIMTArity = 2
+
+// Largest IMT arity supported, limited by the number of Poseidon inputs.
+const MaxIMTArity = 16
+
+// Compute the content ID of the given entries, which is the root of the
+// Merkle tree signed by a POD's signature.  Entries are checked first, so
+// malformed names or values produce a clear error.
+func ComputeContentID(data PodEntries) (*big.Int, error) {
+	return ComputeContentIDWithArity(data, DefaultIMTArity)
+}
+
+// Compute the content ID of the given entries using a lean Poseidon IMT of
+// the given arity.
+//
+// Only DefaultIMTArity produces the canonical content ID which is signed in a
+// POD.  Any other arity produces an incompatible content ID, which is useful
+// only for experimenting with circuit-optimized tree shapes.
+func ComputeContentIDWithArity(data PodEntries, arity int) (*big.Int, error) {
+	allHashes, err := data.ContentPreimage()
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := leanPoseidonIMT(allHashes, arity)
+	if err != nil {
+		return nil, fmt.Errorf("error when computing poseidon IMT: %w", err)
+	}
+	return root, nil
+}
+
+// Returns the leaves of the Merkle tree whose root is the content ID of these
+// entries, in the order they're hashed: the hash of each name followed by the
+// hash of its value, sorted by name.  Useful for comparing against another
+// implementation to find where their encodings differ.
+func (p PodEntries) ContentPreimage() ([]*big.Int, error) {
+	if err := p.Check(); err != nil {
 		return nil, err
 	}
+	return entryLeaves(p, p.sortedNames())
+}
 
-	keys := make([]string, 0, len(data))
-	for k := range data {
-		keys = append(keys, k)
+// Returns the leaves of the content ID tree for the named entries, which
+// should be in sorted order: the hash of each name followed by the hash of its
+// value.
+func entryLeaves(data PodEntries, names []string) ([]*big.Int, error) {
+	workers := runtime.GOMAXPROCS(0)
+	if len(names) < minParallelEntries {
+		workers = 1
 	}
-	sort.Strings(keys)
+	return entryLeavesWithWorkers(data, names, workers)
+}
 
-	var allHashes []*big.Int
-	for _, k := range keys {
-		kh := hashString(k)
-		allHashes = append(allHashes, kh)
+// Fewest entries worth hashing in parallel.  Below this, the cost of
+// coordinating goroutines outweighs the Poseidon hashes saved.
+const minParallelEntries = 16
 
-		vh, err := data[k].Hash()
+// Hashes entries using up to the given number of goroutines.  The leaves are
+// the same regardless of the number of workers.
+func entryLeavesWithWorkers(data PodEntries, names []string, workers int) ([]*big.Int, error) {
+	leaves := make([]*big.Int, 2*len(names))
+	hashEntry := func(i int) error {
+		vh, err := data[names[i]].Hash()
 		if err != nil {
-			return nil, fmt.Errorf("error when hashing pod value: %w", err)
+			return fmt.Errorf("error when hashing pod value: %w", err)
 		}
-		allHashes = append(allHashes, vh)
+		leaves[2*i] = hashString(names[i])
+		leaves[2*i+1] = vh
+		return nil
 	}
 
-	root, err := leanPoseidonIMT(allHashes)
-	if err != nil {
-		return nil, fmt.Errorf("error when computing poseidon IMT: %w", err)
+	if workers <= 1 {
+		for i := range names {
+			if err := hashEntry(i); err != nil {
+				return nil, err
+			}
+		}
+		return leaves, nil
 	}
-	return root, nil
+
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	indices := make(chan int)
+	for w := 0; w < min(workers, len(names)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				if err := hashEntry(i); err != nil {
+					errOnce.Do(func() { firstErr = err })
+				}
+			}
+		}()
+	}
+	for i := range names {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return leaves, nil
+}
+
+// Compute a content ID directly from leaves which are already field elements,
+// such as the witness of a circuit.  The leaves should be the alternating
+// name and value hashes of the entries, sorted by name, exactly as they are
+// fed into the IMT when computing the content ID of PodEntries.
+func ContentIDFromLeaves(leaves []*big.Int) (*big.Int, error) {
+	for i, leaf := range leaves {
+		if leaf == nil || !utils.CheckBigIntInField(leaf) {
+			return nil, fmt.Errorf("leaf %d is not a field element: %v", i, leaf)
+		}
+	}
+	return leanPoseidonIMT(leaves, DefaultIMTArity)
+}
+
+// Formats a content ID as 0x-prefixed hex, zero-padded to 32 bytes.
+func formatContentID(contentID *big.Int) string {
+	return fmt.Sprintf("0x%064x", contentID)
+}
+
+// Computes the root of a lean incremental Merkle tree where each node hashes
+// up to arity children.  A trailing chunk with a single item is carried up to
+// the next level unhashed, while a trailing chunk with more than one item is
+// hashed as-is.
+func leanPoseidonIMT(inputs []*big.Int, arity int) (*big.Int, error) {
+	root, _, err := leanPoseidonIMTLevels(inputs, arity)
+	return root, err
+}
+
+// Computes the root of the binary lean IMT used for content IDs, along with
+// every level of the tree from the inputs up to the root.  levels[0] is a copy
+// of the inputs, and the last level holds only the root.  A node without a
+// sibling is carried up to the next level unchanged.
+func LeanPoseidonIMTWithLevels(inputs []*big.Int) (root *big.Int, levels [][]*big.Int, err error) {
+	return leanPoseidonIMTLevels(inputs, DefaultIMTArity)
 }
 
-func leanPoseidonIMT(inputs []*big.Int) (*big.Int, error) {
+func leanPoseidonIMTLevels(inputs []*big.Int, arity int) (*big.Int, [][]*big.Int, error) {
 	if len(inputs) == 0 {
-		return nil, errors.New("at least one input is required")
+		return nil, nil, errors.New("at least one input is required")
+	}
+	if arity < 2 || arity > MaxIMTArity {
+		return nil, nil, fmt.Errorf("IMT arity %d must be between 2 and %d", arity, MaxIMTArity)
 	}
 
 	items := make([]*big.Int, len(inputs))
 	copy(items, inputs)
+	levels := [][]*big.Int{items}
 
 	for len(items) > 1 {
 		var newItems []*big.Int
-		for i := 0; i < len(items); i += 2 {
-			if i+1 < len(items) {
-				h, err := poseidon.Hash([]*big.Int{items[i], items[i+1]})
+		for i := 0; i < len(items); i += arity {
+			end := min(i+arity, len(items))
+			if end-i > 1 {
+				h, err := poseidon.Hash(items[i:end])
 				if err != nil {
-					return nil, fmt.Errorf("error hashing chunk: %w", err)
+					return nil, nil, fmt.Errorf("error hashing chunk: %w", err)
 				}
 				newItems = append(newItems, h)
 			} else {
@@ -104,6 +260,55 @@ func leanPoseidonIMT(inputs []*big.Int) (*big.Int, error) {
 			}
 		}
 		items = newItems
+		levels = append(levels, items)
+	}
+	return items[0], levels, nil
+}
+
+// Returns the membership proof for leaves[index] in a binary lean IMT: the
+// sibling at each level on the path to the root, and whether the path node is
+// the left (0) or right (1) child at that level.  A node without a sibling is
+// carried up unchanged, so that level contributes nothing to the proof.
+func leanIMTProof(leaves []*big.Int, index int) ([]*big.Int, []int, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, nil, fmt.Errorf("leaf index %d out of range for %d leaves", index, len(leaves))
+	}
+	_, levels, err := LeanPoseidonIMTWithLevels(leaves)
+	if err != nil {
+		return nil, nil, err
+	}
+	var path []*big.Int
+	var indices []int
+	for _, items := range levels[:len(levels)-1] {
+		if sibling := index ^ 1; sibling < len(items) {
+			path = append(path, items[sibling])
+			indices = append(indices, index&1)
+		}
+		index /= 2
+	}
+	return path, indices, nil
+}
+
+// Computes the root of a binary lean IMT from a leaf and its membership proof,
+// as returned by leanIMTProof.
+func leanIMTRootFromProof(leaf *big.Int, path []*big.Int, indices []int) (*big.Int, error) {
+	if len(path) != len(indices) {
+		return nil, fmt.Errorf("proof has %d siblings but %d indices", len(path), len(indices))
+	}
+	node := leaf
+	for i, sibling := range path {
+		var err error
+		switch indices[i] {
+		case 0:
+			node, err = poseidon.Hash([]*big.Int{node, sibling})
+		case 1:
+			node, err = poseidon.Hash([]*big.Int{sibling, node})
+		default:
+			return nil, fmt.Errorf("proof index %d must be 0 or 1, got %d", i, indices[i])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error hashing proof: %w", err)
+		}
 	}
-	return items[0], nil
+	return node, nil
 }
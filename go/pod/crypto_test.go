@@ -0,0 +1,368 @@
+package pod
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/iden3/go-iden3-crypto/v2/poseidon"
+)
+
+func TestContentIDArity(t *testing.T) {
+	entries := PodEntries{
+		"a": NewPodStringValue("a"),
+		"b": NewPodStringValue("b"),
+		"c": NewPodStringValue("c"),
+	}
+
+	canonical, err := ComputeContentID(entries)
+	if err != nil {
+		t.Fatalf("ComputeContentID failed: %v", err)
+	}
+	binary, err := ComputeContentIDWithArity(entries, DefaultIMTArity)
+	if err != nil {
+		t.Fatalf("ComputeContentIDWithArity failed: %v", err)
+	}
+	if canonical.Cmp(binary) != 0 {
+		t.Fatalf("default arity should produce the canonical content ID")
+	}
+
+	// With arity 4, six leaves become one full chunk, and a trailing chunk of
+	// two which is still hashed.
+	var leaves []*big.Int
+	for _, name := range []string{"a", "b", "c"} {
+		vh, err := entries[name].Hash()
+		if err != nil {
+			t.Fatalf("Hash failed: %v", err)
+		}
+		leaves = append(leaves, hashString(name), vh)
+	}
+	first, err := poseidon.Hash(leaves[0:4])
+	if err != nil {
+		t.Fatalf("hash failed: %v", err)
+	}
+	second, err := poseidon.Hash(leaves[4:6])
+	if err != nil {
+		t.Fatalf("hash failed: %v", err)
+	}
+	expected, err := poseidon.Hash([]*big.Int{first, second})
+	if err != nil {
+		t.Fatalf("hash failed: %v", err)
+	}
+	quaternary, err := ComputeContentIDWithArity(entries, 4)
+	if err != nil {
+		t.Fatalf("ComputeContentIDWithArity failed: %v", err)
+	}
+	if quaternary.Cmp(expected) != 0 {
+		t.Fatalf("unexpected arity 4 content ID %v, expected %v", quaternary, expected)
+	}
+	if quaternary.Cmp(canonical) == 0 {
+		t.Fatalf("arity 4 content ID should differ from canonical")
+	}
+
+	for _, arity := range []int{-1, 0, 1, MaxIMTArity + 1} {
+		if _, err := ComputeContentIDWithArity(entries, arity); err == nil {
+			t.Fatalf("expected arity %d to be rejected", arity)
+		}
+	}
+}
+
+func TestContentIDFromLeaves(t *testing.T) {
+	entries := PodEntries{
+		"b": NewPodBooleanValue(true),
+		"a": NewPodStringValue("a"),
+	}
+	expected, err := ComputeContentID(entries)
+	if err != nil {
+		t.Fatalf("ComputeContentID failed: %v", err)
+	}
+
+	aHash, err := entries["a"].Hash()
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	bHash, err := entries["b"].Hash()
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	contentID, err := ContentIDFromLeaves([]*big.Int{hashString("a"), aHash, hashString("b"), bHash})
+	if err != nil {
+		t.Fatalf("ContentIDFromLeaves failed: %v", err)
+	}
+	if contentID.Cmp(expected) != 0 {
+		t.Fatalf("content ID from leaves %v differs from %v", contentID, expected)
+	}
+
+	if _, err := ContentIDFromLeaves(nil); err == nil {
+		t.Fatalf("expected empty leaves to fail")
+	}
+	if _, err := ContentIDFromLeaves([]*big.Int{aHash, nil}); err == nil {
+		t.Fatalf("expected nil leaf to fail")
+	}
+	if _, err := ContentIDFromLeaves([]*big.Int{aHash, big.NewInt(-1)}); err == nil {
+		t.Fatalf("expected negative leaf to fail")
+	}
+}
+
+func TestContentPreimage(t *testing.T) {
+	entries := PodEntries{
+		"b": NewPodBooleanValue(true),
+		"a": NewPodStringValue("a"),
+	}
+	preimage, err := entries.ContentPreimage()
+	if err != nil {
+		t.Fatalf("ContentPreimage failed: %v", err)
+	}
+	bHash, err := entries["b"].Hash()
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	expected := []*big.Int{hashString("a"), hashString("a"), hashString("b"), bHash}
+	if len(preimage) != len(expected) {
+		t.Fatalf("expected %d leaves, got %d", len(expected), len(preimage))
+	}
+	for i := range expected {
+		if preimage[i].Cmp(expected[i]) != 0 {
+			t.Fatalf("leaf %d is %v, expected %v", i, preimage[i], expected[i])
+		}
+	}
+
+	contentID, err := ContentIDFromLeaves(preimage)
+	if err != nil {
+		t.Fatalf("ContentIDFromLeaves failed: %v", err)
+	}
+	expectedID, err := ComputeContentID(entries)
+	if err != nil {
+		t.Fatalf("ComputeContentID failed: %v", err)
+	}
+	if contentID.Cmp(expectedID) != 0 {
+		t.Fatalf("content ID from preimage %v differs from %v", contentID, expectedID)
+	}
+
+	if _, err := (PodEntries{"bad name": NewPodNullValue()}).ContentPreimage(); err == nil {
+		t.Fatalf("expected invalid name to fail")
+	}
+}
+
+func TestLeanPoseidonIMTWithLevels(t *testing.T) {
+	a, b, c := big.NewInt(1), big.NewInt(2), big.NewInt(3)
+	ab, err := poseidon.Hash([]*big.Int{a, b})
+	if err != nil {
+		t.Fatalf("poseidon.Hash failed: %v", err)
+	}
+	// The odd leaf c is carried up unhashed.
+	abc, err := poseidon.Hash([]*big.Int{ab, c})
+	if err != nil {
+		t.Fatalf("poseidon.Hash failed: %v", err)
+	}
+
+	root, levels, err := LeanPoseidonIMTWithLevels([]*big.Int{a, b, c})
+	if err != nil {
+		t.Fatalf("LeanPoseidonIMTWithLevels failed: %v", err)
+	}
+	if root.Cmp(abc) != 0 {
+		t.Fatalf("unexpected root %v, expected %v", root, abc)
+	}
+	expected := [][]*big.Int{{a, b, c}, {ab, c}, {abc}}
+	if len(levels) != len(expected) {
+		t.Fatalf("expected %d levels, got %d", len(expected), len(levels))
+	}
+	for i := range expected {
+		if len(levels[i]) != len(expected[i]) {
+			t.Fatalf("level %d has %d nodes, expected %d", i, len(levels[i]), len(expected[i]))
+		}
+		for j := range expected[i] {
+			if levels[i][j].Cmp(expected[i][j]) != 0 {
+				t.Fatalf("level %d node %d is %v, expected %v", i, j, levels[i][j], expected[i][j])
+			}
+		}
+	}
+
+	preimage, err := testEntries(5).ContentPreimage()
+	if err != nil {
+		t.Fatalf("ContentPreimage failed: %v", err)
+	}
+	root, _, err = LeanPoseidonIMTWithLevels(preimage)
+	if err != nil {
+		t.Fatalf("LeanPoseidonIMTWithLevels failed: %v", err)
+	}
+	expectedRoot, err := ComputeContentID(testEntries(5))
+	if err != nil {
+		t.Fatalf("ComputeContentID failed: %v", err)
+	}
+	if root.Cmp(expectedRoot) != 0 {
+		t.Fatalf("root %v differs from content ID %v", root, expectedRoot)
+	}
+
+	if _, _, err := LeanPoseidonIMTWithLevels(nil); err == nil {
+		t.Fatalf("expected empty inputs to fail")
+	}
+}
+
+func TestHashBytesReader(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 100000)
+
+	streamed, err := HashBytesReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("HashBytesReader failed: %v", err)
+	}
+	value, err := NewPodBytesValueFromReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("NewPodBytesValueFromReader failed: %v", err)
+	}
+	valueHash, err := value.Hash()
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	if streamed.Cmp(valueHash) != 0 || streamed.Cmp(hashBytes(data)) != 0 {
+		t.Fatalf("streamed hash %v differs from in-memory hash %v", streamed, valueHash)
+	}
+
+	if _, err := HashBytesReader(iotest.ErrReader(errors.New("read failed"))); err == nil {
+		t.Fatalf("expected read error to fail")
+	}
+}
+
+// A reader of endless zeros, which counts the bytes read from it.
+type countingZeroReader struct {
+	read int
+}
+
+func (r *countingZeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	r.read += len(p)
+	return len(p), nil
+}
+
+func TestBytesReaderLimit(t *testing.T) {
+	defer func(bytesLen int) { MaxBytesLen = bytesLen }(MaxBytesLen)
+	MaxBytesLen = 1000
+
+	if _, err := NewPodBytesValueFromReader(bytes.NewReader(make([]byte, 1000))); err != nil {
+		t.Fatalf("expected bytes at the limit to be read: %v", err)
+	}
+	reader := &countingZeroReader{}
+	if _, err := NewPodBytesValueFromReader(reader); err == nil || !strings.Contains(err.Error(), "more than the maximum of 1000 bytes") {
+		t.Fatalf("expected endless reader to fail, got %v", err)
+	}
+	if reader.read > MaxBytesLen+1 {
+		t.Fatalf("read %d bytes past the limit of %d", reader.read, MaxBytesLen)
+	}
+}
+
+func TestKnownContentID(t *testing.T) {
+	// Same entries as the TypeScript compatibility POD, whose signature
+	// verifies against this content ID.
+	const jsonFromTypeScript = `{"I1":1,"_2I":-123,"_s2":"!@#$%%%^&","bigI1":9007199254740991,"bigI2":-9007199254740991,"c1":{"cryptographic":123},"c2":{"cryptographic":"0x1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"},"pk1":{"eddsa_pubkey":"xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4"},"s1":"hello there"}`
+	var entries PodEntries
+	if err := json.Unmarshal([]byte(jsonFromTypeScript), &entries); err != nil {
+		t.Fatalf("Failed to parse entries: %v", err)
+	}
+	contentID, err := ComputeContentID(entries)
+	if err != nil {
+		t.Fatalf("ComputeContentID failed: %v", err)
+	}
+	const expected = "0x1cfe0e0ab2cedc9ba6656f72836bbd2052d5c7193b1da85248c131420c1dad1a"
+	if formatContentID(contentID) != expected {
+		t.Fatalf("unexpected content ID %s, expected %s", formatContentID(contentID), expected)
+	}
+}
+
+func TestRandSource(t *testing.T) {
+	defer func(original io.Reader) { RandSource = original }(RandSource)
+
+	RandSource = bytes.NewReader([]byte{1, 2, 3, 4})
+	b, err := randomBytes(4)
+	if err != nil {
+		t.Fatalf("randomBytes failed: %v", err)
+	}
+	if !bytes.Equal(b, []byte{1, 2, 3, 4}) {
+		t.Fatalf("expected bytes from injected source, got %v", b)
+	}
+
+	// The source is exhausted, so a short read must fail rather than return
+	// zeros.
+	if _, err := randomBytes(1); err == nil {
+		t.Fatalf("expected exhausted source to fail")
+	}
+}
+
+func TestComputeContentIDChecksEntries(t *testing.T) {
+	if _, err := ComputeContentID(PodEntries{"bad name": NewPodNullValue()}); err == nil || !strings.Contains(err.Error(), "invalid POD name") {
+		t.Fatalf("expected invalid name error, got %v", err)
+	}
+	if _, err := ComputeContentID(PodEntries{"count": PodValue{ValueType: PodIntValue}}); err == nil || !strings.Contains(err.Error(), "should not be nil") {
+		t.Fatalf("expected invalid value error, got %v", err)
+	}
+}
+
+// Returns n cryptographic entries named entry0, entry1, etc.
+func testEntries(n int) PodEntries {
+	entries := PodEntries{}
+	for i := 0; i < n; i++ {
+		entries[fmt.Sprintf("entry%d", i)] = PodValue{ValueType: PodCryptographicValue, BigVal: big.NewInt(int64(i) * 1234567890)}
+	}
+	return entries
+}
+
+func TestParallelEntryLeaves(t *testing.T) {
+	entries := testEntries(64)
+	entries["message"] = NewPodStringValue("hello")
+	names := entries.sortedNames()
+	sequential, err := entryLeavesWithWorkers(entries, names, 1)
+	if err != nil {
+		t.Fatalf("entryLeavesWithWorkers failed: %v", err)
+	}
+	for _, workers := range []int{2, 7, 100} {
+		parallel, err := entryLeavesWithWorkers(entries, names, workers)
+		if err != nil {
+			t.Fatalf("entryLeavesWithWorkers failed: %v", err)
+		}
+		for i := range sequential {
+			if sequential[i].Cmp(parallel[i]) != 0 {
+				t.Fatalf("leaf %d differs with %d workers", i, workers)
+			}
+		}
+	}
+
+	entries["bad"] = PodValue{ValueType: PodEdDSAPubkeyValue, StringVal: "not a key"}
+	if _, err := entryLeavesWithWorkers(entries, entries.sortedNames(), 4); err == nil {
+		t.Fatalf("expected hash error to be returned")
+	}
+}
+
+func BenchmarkEntryLeaves64Sequential(b *testing.B) {
+	entries := testEntries(64)
+	names := entries.sortedNames()
+	for i := 0; i < b.N; i++ {
+		if _, err := entryLeavesWithWorkers(entries, names, 1); err != nil {
+			b.Fatalf("entryLeavesWithWorkers failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkEntryLeaves64Parallel(b *testing.B) {
+	entries := testEntries(64)
+	names := entries.sortedNames()
+	for i := 0; i < b.N; i++ {
+		if _, err := entryLeavesWithWorkers(entries, names, runtime.GOMAXPROCS(0)); err != nil {
+			b.Fatalf("entryLeavesWithWorkers failed: %v", err)
+		}
+	}
+}
+
+func BenchmarkComputeContentID64(b *testing.B) {
+	entries := testEntries(64)
+	for i := 0; i < b.N; i++ {
+		if _, err := ComputeContentID(entries); err != nil {
+			b.Fatalf("ComputeContentID failed: %v", err)
+		}
+	}
+}
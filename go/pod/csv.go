@@ -0,0 +1,48 @@
+package pod
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Writes these entries as CSV, with a header row followed by one "name,type,
+// value" row per entry in canonical sorted order.  Values are rendered as
+// strings: int and cryptographic as decimal, dates as RFC 3339, bytes as
+// Base64, and null as an empty string.
+func (p PodEntries) WriteCSV(w io.Writer) error {
+	if err := p.Check(); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"name", "type", "value"}); err != nil {
+		return fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, name := range p.sortedNames() {
+		value := p[name]
+		if err := writer.Write([]string{name, string(value.ValueType), value.csvString()}); err != nil {
+			return fmt.Errorf("failed to write CSV row for %s: %w", name, err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (p PodValue) csvString() string {
+	switch p.ValueType {
+	case PodStringValue, PodEdDSAPubkeyValue:
+		return p.StringVal
+	case PodBooleanValue:
+		return strconv.FormatBool(p.BoolVal)
+	case PodIntValue, PodCryptographicValue:
+		return p.BigVal.String()
+	case PodBytesValue:
+		return noPadB64.EncodeToString(p.BytesVal)
+	case PodDateValue:
+		return p.TimeVal.UTC().Format(time.RFC3339Nano)
+	default:
+		return ""
+	}
+}
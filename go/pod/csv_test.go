@@ -0,0 +1,40 @@
+package pod
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestWriteCSV(t *testing.T) {
+	entries := PodEntries{
+		"A": PodValue{ValueType: PodIntValue, BigVal: big.NewInt(-7)},
+		"C": PodValue{ValueType: PodBooleanValue, BoolVal: true},
+		"D": PodValue{ValueType: PodDateValue, TimeVal: time.Date(2025, 1, 1, 0, 0, 0, 5e6, time.UTC)},
+		"J": PodValue{ValueType: PodBytesValue, BytesVal: []byte{0x01, 0x02, 0x03}},
+		"K": PodValue{ValueType: PodCryptographicValue, BigVal: big.NewInt(1234567890)},
+		"N": PodValue{ValueType: PodNullValue},
+		"S": PodValue{ValueType: PodStringValue, StringVal: "hello, \"world\""},
+	}
+	var buf bytes.Buffer
+	if err := entries.WriteCSV(&buf); err != nil {
+		t.Fatalf("WriteCSV failed: %v", err)
+	}
+	expected := "name,type,value\n" +
+		"A,int,-7\n" +
+		"C,boolean,true\n" +
+		"D,date,2025-01-01T00:00:00.005Z\n" +
+		"J,bytes,AQID\n" +
+		"K,cryptographic,1234567890\n" +
+		"N,null,\n" +
+		"S,string,\"hello, \"\"world\"\"\"\n"
+	if buf.String() != expected {
+		t.Fatalf("unexpected CSV:\n%s", buf.String())
+	}
+
+	entries["bad name"] = NewPodNullValue()
+	if err := entries.WriteCSV(&buf); err == nil {
+		t.Fatalf("expected invalid entries to fail")
+	}
+}
@@ -0,0 +1,236 @@
+package pod
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// An entry revealed from a POD without revealing its other entries, along with
+// a proof that it's a member of the POD's content ID tree.  Path and Indices
+// are the membership proof of the entry's value hash, whose first sibling is
+// always the hash of the entry's name.
+type DisclosedEntry struct {
+	Value   PodValue
+	Path    []*big.Int
+	Indices []int
+}
+
+// The required entries of a disclosure policy which weren't disclosed.  Wraps
+// ErrMissingEntry.
+type MissingDisclosuresError struct {
+	Missing []string
+}
+
+func (e *MissingDisclosuresError) Error() string {
+	return fmt.Sprintf("%v: required entries not disclosed: %s", ErrMissingEntry, strings.Join(e.Missing, ", "))
+}
+
+func (e *MissingDisclosuresError) Unwrap() error {
+	return ErrMissingEntry
+}
+
+// Returns the named entries of this POD with membership proofs, which can be
+// verified against the content ID and signature without the other entries.
+// The signature is not verified.
+func (p *Pod) Disclose(names ...string) (map[string]DisclosedEntry, error) {
+	disclosed := make(map[string]DisclosedEntry, len(names))
+	for _, name := range names {
+		_, path, indices, err := p.EntryProof(name)
+		if err != nil {
+			return nil, err
+		}
+		disclosed[name] = DisclosedEntry{Value: p.Entries[name], Path: path, Indices: indices}
+	}
+	return disclosed, nil
+}
+
+// Verify a signature on a content ID, and that each disclosed entry is a
+// member of the tree with that content ID.  Returns false if the signature or
+// any membership proof doesn't match, and an error if either is malformed.
+func VerifyDisclosure(
+	contentID *big.Int,
+	signature string,
+	signerPublicKey string,
+	disclosed map[string]DisclosedEntry,
+) (bool, error) {
+	publicKey, err := decodePublicKey(signerPublicKey)
+	if err != nil {
+		return false, err
+	}
+	ok, err := verifyContentIDSignature(contentID, signature, publicKey)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	for name, entry := range disclosed {
+		ok, err := entry.verifyMembership(name, contentID)
+		if err != nil {
+			return false, fmt.Errorf("disclosed entry %q: %w", name, err)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Verify a disclosure as in VerifyDisclosure, and check that every required
+// entry was disclosed.  Returns a *MissingDisclosuresError listing any
+// required entries which weren't.
+func VerifyDisclosurePolicy(
+	contentID *big.Int,
+	signature string,
+	signerPublicKey string,
+	disclosed map[string]DisclosedEntry,
+	required []string,
+) (bool, error) {
+	ok, err := VerifyDisclosure(contentID, signature, signerPublicKey, disclosed)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	var missing []string
+	for _, name := range required {
+		if _, ok := disclosed[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return false, &MissingDisclosuresError{Missing: missing}
+	}
+	return true, nil
+}
+
+// Checks that this entry's value is in the content ID tree under the given
+// name.
+func (e *DisclosedEntry) verifyMembership(name string, contentID *big.Int) (bool, error) {
+	if err := e.Value.Check(); err != nil {
+		return false, err
+	}
+	valueHash, err := e.Value.Hash()
+	if err != nil {
+		return false, err
+	}
+	return verifyValueHashMembership(name, valueHash, e.Path, e.Indices, contentID)
+}
+
+// Checks that a value hash is in the content ID tree under the given name.  The
+// value's leaf is always the right child of its name's leaf, so the proof must
+// start with the name hash on the left.
+func verifyValueHashMembership(
+	name string,
+	valueHash *big.Int,
+	path []*big.Int,
+	indices []int,
+	contentID *big.Int,
+) (bool, error) {
+	if err := CheckPodName(name); err != nil {
+		return false, err
+	}
+	if valueHash == nil || contentID == nil {
+		return false, fmt.Errorf("value hash and content ID should not be nil")
+	}
+	if len(path) == 0 || len(indices) == 0 {
+		return false, fmt.Errorf("membership proof is empty")
+	}
+	if indices[0] != 1 || path[0] == nil || path[0].Cmp(hashString(name)) != 0 {
+		return false, nil
+	}
+	root, err := leanIMTRootFromProof(valueHash, path, indices)
+	if err != nil {
+		return false, err
+	}
+	return root.Cmp(contentID) == 0, nil
+}
+
+// A proof that two PODs have an entry with the same name and value, which
+// reveals only the hash of the value.
+type SharedValueProof struct {
+	Name      string
+	ValueHash *big.Int
+	PathA     []*big.Int
+	IndicesA  []int
+	PathB     []*big.Int
+	IndicesB  []int
+}
+
+// Returns a proof that the named entry has the same value in both PODs.
+// Returns an error wrapping ErrMissingEntry if either POD lacks the entry, or
+// if the values differ.  The signatures are not verified.
+func SharedEntryProof(a *Pod, b *Pod, name string) (*SharedValueProof, error) {
+	valueHash, pathA, indicesA, err := a.EntryProof(name)
+	if err != nil {
+		return nil, fmt.Errorf("first POD: %w", err)
+	}
+	valueHashB, pathB, indicesB, err := b.EntryProof(name)
+	if err != nil {
+		return nil, fmt.Errorf("second POD: %w", err)
+	}
+	if valueHash.Cmp(valueHashB) != 0 {
+		return nil, fmt.Errorf("entry %q has different values in the two PODs", name)
+	}
+	return &SharedValueProof{
+		Name:      name,
+		ValueHash: valueHash,
+		PathA:     pathA,
+		IndicesA:  indicesA,
+		PathB:     pathB,
+		IndicesB:  indicesB,
+	}, nil
+}
+
+// Verify that the entry named in a SharedValueProof has the same value in the
+// trees with the two given content IDs.  This doesn't verify that the content
+// IDs were signed, which the caller should check separately, e.g. with
+// VerifyDisclosure.
+func VerifySharedEntryProof(contentIDA *big.Int, contentIDB *big.Int, proof *SharedValueProof) (bool, error) {
+	if proof == nil {
+		return false, fmt.Errorf("proof should not be nil")
+	}
+	ok, err := verifyValueHashMembership(proof.Name, proof.ValueHash, proof.PathA, proof.IndicesA, contentIDA)
+	if err != nil || !ok {
+		return ok, err
+	}
+	return verifyValueHashMembership(proof.Name, proof.ValueHash, proof.PathB, proof.IndicesB, contentIDB)
+}
+
+// Returns the hash of the named entry's value, and its membership proof in the
+// content ID tree: the sibling at each level on the path to the root, and
+// whether the path node is the left (0) or right (1) child at that level.
+// The first sibling is always the hash of the entry's name.  Returns an error
+// wrapping ErrMissingEntry if the entry doesn't exist.  The signature is not
+// verified.
+func (p *Pod) EntryProof(name string) (valueHash *big.Int, path []*big.Int, indices []int, err error) {
+	if err := p.Entries.Check(); err != nil {
+		return nil, nil, nil, err
+	}
+	sortedNames := p.Entries.sortedNames()
+	i := sort.SearchStrings(sortedNames, name)
+	if i == len(sortedNames) || sortedNames[i] != name {
+		return nil, nil, nil, fmt.Errorf("%w: %q", ErrMissingEntry, name)
+	}
+	leaves, err := entryLeaves(p.Entries, sortedNames)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	path, indices, err = leanIMTProof(leaves, 2*i+1)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return leaves[2*i+1], path, indices, nil
+}
+
+// Checks that valueHash is the value of the named entry in the tree with the
+// given root, using a membership proof as returned by EntryProof.  Returns
+// false if the proof is malformed or doesn't match.
+func VerifyEntryProof(root *big.Int, name string, valueHash *big.Int, path []*big.Int, indices []int) bool {
+	for _, sibling := range path {
+		if sibling == nil {
+			return false
+		}
+	}
+	ok, err := verifyValueHashMembership(name, valueHash, path, indices, root)
+	return err == nil && ok
+}
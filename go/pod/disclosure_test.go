@@ -0,0 +1,180 @@
+package pod
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestDisclosure(t *testing.T) {
+	// Five entries give ten leaves, so some nodes are carried up without a
+	// sibling.
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"age":     PodValue{ValueType: PodIntValue, BigVal: big.NewInt(42)},
+		"email":   NewPodStringValue("alice@example.com"),
+		"name":    NewPodStringValue("Alice"),
+		"role":    NewPodStringValue("admin"),
+		"zipcode": NewPodStringValue("94110"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	contentID, err := ComputeContentID(pod.Entries)
+	if err != nil {
+		t.Fatalf("ComputeContentID failed: %v", err)
+	}
+
+	for _, name := range []string{"age", "email", "name", "role", "zipcode"} {
+		disclosed, err := pod.Disclose(name)
+		if err != nil {
+			t.Fatalf("Disclose failed: %v", err)
+		}
+		ok, err := VerifyDisclosure(contentID, pod.Signature, pod.SignerPublicKey, disclosed)
+		if err != nil || !ok {
+			t.Fatalf("expected disclosure of %s to verify: %v", name, err)
+		}
+	}
+
+	disclosed, err := pod.Disclose("name", "role")
+	if err != nil {
+		t.Fatalf("Disclose failed: %v", err)
+	}
+	ok, err := VerifyDisclosurePolicy(contentID, pod.Signature, pod.SignerPublicKey, disclosed, []string{"role"})
+	if err != nil || !ok {
+		t.Fatalf("expected policy to be satisfied: %v", err)
+	}
+	_, err = VerifyDisclosurePolicy(contentID, pod.Signature, pod.SignerPublicKey, disclosed, []string{"role", "age", "email"})
+	var missingErr *MissingDisclosuresError
+	if !errors.As(err, &missingErr) || !errors.Is(err, ErrMissingEntry) {
+		t.Fatalf("expected missing disclosures error, got %v", err)
+	}
+	if len(missingErr.Missing) != 2 || missingErr.Missing[0] != "age" || missingErr.Missing[1] != "email" {
+		t.Fatalf("unexpected missing entries %v", missingErr.Missing)
+	}
+
+	// A changed value, or a value disclosed under another name, doesn't verify.
+	changed := disclosed["role"]
+	changed.Value = NewPodStringValue("superadmin")
+	ok, err = VerifyDisclosure(contentID, pod.Signature, pod.SignerPublicKey, map[string]DisclosedEntry{"role": changed})
+	if err != nil || ok {
+		t.Fatalf("expected changed value to fail: %v", err)
+	}
+	ok, err = VerifyDisclosure(contentID, pod.Signature, pod.SignerPublicKey, map[string]DisclosedEntry{"admin": disclosed["role"]})
+	if err != nil || ok {
+		t.Fatalf("expected renamed entry to fail: %v", err)
+	}
+
+	// The content ID must be the one which was signed.
+	otherID := new(big.Int).Add(contentID, big.NewInt(1))
+	ok, err = VerifyDisclosure(otherID, pod.Signature, pod.SignerPublicKey, disclosed)
+	if err != nil || ok {
+		t.Fatalf("expected wrong content ID to fail: %v", err)
+	}
+
+	if _, err := pod.Disclose("missing"); !errors.Is(err, ErrMissingEntry) {
+		t.Fatalf("expected missing entry error, got %v", err)
+	}
+}
+
+func TestSharedEntryProof(t *testing.T) {
+	a, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"email": NewPodStringValue("alice@example.com"),
+		"name":  NewPodStringValue("Alice"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	b, err := CreatePod("0101020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"age":    PodValue{ValueType: PodIntValue, BigVal: big.NewInt(42)},
+		"email":  NewPodStringValue("alice@example.com"),
+		"member": NewPodBooleanValue(true),
+		"name":   NewPodStringValue("A. Smith"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	contentIDA, err := ComputeContentID(a.Entries)
+	if err != nil {
+		t.Fatalf("ComputeContentID failed: %v", err)
+	}
+	contentIDB, err := ComputeContentID(b.Entries)
+	if err != nil {
+		t.Fatalf("ComputeContentID failed: %v", err)
+	}
+
+	proof, err := SharedEntryProof(a, b, "email")
+	if err != nil {
+		t.Fatalf("SharedEntryProof failed: %v", err)
+	}
+	ok, err := VerifySharedEntryProof(contentIDA, contentIDB, proof)
+	if err != nil || !ok {
+		t.Fatalf("expected shared entry proof to verify: %v", err)
+	}
+	ok, err = VerifySharedEntryProof(contentIDB, contentIDA, proof)
+	if err != nil || ok {
+		t.Fatalf("expected swapped content IDs to fail: %v", err)
+	}
+	proof.Name = "name"
+	ok, err = VerifySharedEntryProof(contentIDA, contentIDB, proof)
+	if err != nil || ok {
+		t.Fatalf("expected proof under another name to fail: %v", err)
+	}
+
+	if _, err := SharedEntryProof(a, b, "name"); err == nil {
+		t.Fatalf("expected different values to fail")
+	}
+	if _, err := SharedEntryProof(a, b, "age"); !errors.Is(err, ErrMissingEntry) {
+		t.Fatalf("expected missing entry error, got %v", err)
+	}
+}
+
+func TestEntryProof(t *testing.T) {
+	// Tree sizes which carry odd nodes up at different levels.
+	for _, n := range []int{1, 3, 5, 6} {
+		pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", testEntries(n))
+		if err != nil {
+			t.Fatalf("CreatePod failed: %v", err)
+		}
+		contentID, err := pod.ContentID()
+		if err != nil {
+			t.Fatalf("ContentID failed: %v", err)
+		}
+		for name, value := range pod.Entries {
+			valueHash, path, indices, err := pod.EntryProof(name)
+			if err != nil {
+				t.Fatalf("EntryProof failed: %v", err)
+			}
+			expectedHash, err := value.Hash()
+			if err != nil {
+				t.Fatalf("Hash failed: %v", err)
+			}
+			if valueHash.Cmp(expectedHash) != 0 {
+				t.Fatalf("value hash %v differs from %v", valueHash, expectedHash)
+			}
+			if !VerifyEntryProof(contentID, name, valueHash, path, indices) {
+				t.Fatalf("expected proof of %s in %d entries to verify", name, n)
+			}
+			if VerifyEntryProof(contentID, name, new(big.Int).Add(valueHash, big.NewInt(1)), path, indices) {
+				t.Fatalf("expected proof of wrong value to fail")
+			}
+			if VerifyEntryProof(contentID, name, valueHash, path, indices[1:]) {
+				t.Fatalf("expected proof with missing index to fail")
+			}
+			if VerifyEntryProof(contentID, name+"x", valueHash, path, indices) {
+				t.Fatalf("expected proof under another name to fail")
+			}
+			// The name hash is also a leaf of the tree, but not in a value position.
+			if len(path) > 1 && VerifyEntryProof(contentID, name, path[0], path[1:], indices[1:]) {
+				t.Fatalf("expected proof of an inner node to fail")
+			}
+		}
+	}
+
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", testEntries(2))
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	if _, _, _, err := pod.EntryProof("missing"); !errors.Is(err, ErrMissingEntry) {
+		t.Fatalf("expected missing entry error, got %v", err)
+	}
+}
@@ -1,20 +1,48 @@
 package pod
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"math/big"
+	"net/url"
 	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
 )
 
 // The keys and values stored in a POD
 type PodEntries map[string]PodValue
 
+// Limits on the size of entries, checked along with their format so that
+// oversized input fails when it's parsed rather than when it's hashed.  The
+// TypeScript library has no fixed limits, so the defaults are generous enough
+// for any practical POD.  A limit of zero or less disables the check.
+var (
+	// Most entries allowed in a POD.  Default 4096.
+	MaxEntries = 4096
+
+	// Longest string value allowed, in bytes of UTF-8.  Default 1 MiB.
+	MaxStringBytes = 1 << 20
+
+	// Longest bytes value allowed.  Default 1 MiB.
+	MaxBytesLen = 1 << 20
+)
+
 // Checks that all the names and values in entries are well-formed and in
-// valid ranges for their types.  Returns nil if all are legal.
+// valid ranges for their types, and within the size limits.  Returns nil if
+// all are legal.
 func (p *PodEntries) Check() error {
 	if p == nil || *p == nil {
 		return fmt.Errorf("PodEntries should not be nil")
 	}
+	if MaxEntries > 0 {
+		if err := p.CheckMaxEntries(MaxEntries); err != nil {
+			return err
+		}
+	}
 	for n, v := range *p {
 		err := CheckPodName(n)
 		if err != nil {
@@ -28,8 +56,221 @@ func (p *PodEntries) Check() error {
 	return nil
 }
 
-// Regular expression defining the legal format for the name of a POD entry.
-var PodNameRegex = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+// Returns the named entry, and whether it exists.
+func (p PodEntries) Get(name string) (PodValue, bool) {
+	value, ok := p[name]
+	return value, ok
+}
+
+// Adds or replaces the named entry, after checking that the name and value are
+// legal.  Returns an error without changing the entries if not.  Creates the
+// map if it's nil.
+func (p *PodEntries) Set(name string, value PodValue) error {
+	if err := CheckPodName(name); err != nil {
+		return err
+	}
+	if err := value.checkWithNamePrefix(fmt.Sprintf("%s: ", name)); err != nil {
+		return err
+	}
+	if *p == nil {
+		*p = PodEntries{}
+	}
+	(*p)[name] = value
+	return nil
+}
+
+// Returns a deep copy of these entries, so that modifying the copy or its
+// values never affects the original.
+func (p PodEntries) Clone() PodEntries {
+	if p == nil {
+		return nil
+	}
+	result := make(PodEntries, len(p))
+	for name, value := range p {
+		result[name] = value.clone()
+	}
+	return result
+}
+
+// Returns the names of these entries in canonical sorted order.
+func (p PodEntries) sortedNames() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Renders these entries for debugging, one per line sorted by name, with the
+// type of each value always shown, e.g. "A: int(321)".  Entries aren't checked,
+// so malformed values are shown as they are.
+func (p PodEntries) Dump() string {
+	var b strings.Builder
+	for _, name := range p.sortedNames() {
+		fmt.Fprintf(&b, "%s: %v\n", name, p[name])
+	}
+	return b.String()
+}
+
+// Checks that there are no more than n entries, e.g. to match the fixed
+// capacity of a circuit.  Returns nil if so.
+func (p PodEntries) CheckMaxEntries(n int) error {
+	if len(p) > n {
+		return fmt.Errorf("POD has %d entries, more than the maximum of %d", len(p), n)
+	}
+	return nil
+}
+
+// Checks that none of these entries use a reserved name, e.g. to prevent
+// clients injecting entries with protocol meaning.  Each reserved string is an
+// exact name, or a prefix if it ends with "*", as in "_src_*".  Returns nil if
+// no entries are reserved.
+func (p PodEntries) CheckReservedNames(reserved []string) error {
+	for name := range p {
+		for _, r := range reserved {
+			prefix, isPrefix := strings.CutSuffix(r, "*")
+			if name == r || (isPrefix && strings.HasPrefix(name, prefix)) {
+				return fmt.Errorf("POD name \"%s\" is reserved by \"%s\"", name, r)
+			}
+		}
+	}
+	return nil
+}
+
+// Returns a copy of these entries without any null values.  A null entry is a
+// real entry which contributes to the content ID, as in other POD libraries,
+// so entries with and without nulls produce different content IDs.  Call this
+// before signing or comparing if null should be treated as absent.
+func (p PodEntries) WithoutNulls() PodEntries {
+	result := make(PodEntries, len(p))
+	for name, value := range p {
+		if value.ValueType != PodNullValue {
+			result[name] = value
+		}
+	}
+	return result
+}
+
+// Builds entries from URL query parameters, inferring the type of each value:
+// decimal integers become int, "true" and "false" become boolean, RFC 3339
+// timestamps become date, and anything else becomes string.  Integers out of
+// the int range are an error, not a string.  A parameter given more than once
+// is an error, since it's ambiguous which value was intended.
+func EntriesFromValues(v url.Values) (PodEntries, error) {
+	entries := make(PodEntries, len(v))
+	for name, values := range v {
+		if len(values) != 1 {
+			return nil, fmt.Errorf("query parameter \"%s\" must have exactly one value, got %d", name, len(values))
+		}
+		value, err := inferPodValue(values[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		entries[name] = value
+	}
+	if err := entries.Check(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Builds entries from a map of Go values, choosing the type of each value as
+// NewPodValue does.
+func PodEntriesFromMap(m map[string]interface{}) (PodEntries, error) {
+	entries := make(PodEntries, len(m))
+	for name, v := range m {
+		value, err := NewPodValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		entries[name] = value
+	}
+	if err := entries.Check(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Returns the entries as a map of Go values: string for string and
+// eddsa_pubkey, bool for boolean, int64 for int, *big.Int for cryptographic,
+// []byte for bytes, time.Time for date, and nil for null.  Numbers and bytes are
+// copies.  PodEntriesFromMap reverses this, except that public keys become
+// strings, and cryptographics in int range become ints.  The entries must be
+// well-formed, as checked by Check.
+func (p PodEntries) ToMap() map[string]interface{} {
+	m := make(map[string]interface{}, len(p))
+	for name, value := range p {
+		switch value.ValueType {
+		case PodStringValue, PodEdDSAPubkeyValue:
+			m[name] = value.StringVal
+		case PodBooleanValue:
+			m[name] = value.BoolVal
+		case PodIntValue:
+			m[name] = value.BigVal.Int64()
+		case PodCryptographicValue:
+			m[name] = new(big.Int).Set(value.BigVal)
+		case PodBytesValue:
+			m[name] = append([]byte{}, value.BytesVal...)
+		case PodDateValue:
+			m[name] = value.TimeVal
+		default:
+			m[name] = nil
+		}
+	}
+	return m
+}
+
+// Infers the type of a value given as an untyped string.
+func inferPodValue(s string) (PodValue, error) {
+	if i, ok := new(big.Int).SetString(s, 10); ok {
+		return NewPodIntValue(i)
+	}
+	switch s {
+	case "true":
+		return NewPodBooleanValue(true), nil
+	case "false":
+		return NewPodBooleanValue(false), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return NewPodDateValue(t)
+	}
+	return NewPodStringValue(s), nil
+}
+
+// Regular expression defining the legal format for the name of a POD entry,
+// used by CheckPodName.  The default allows only ASCII letters, digits, and
+// underscores.  It can be replaced, e.g. with UnicodePodNameRegex, to accept
+// names which other POD libraries might not, so this should be done only when
+// every party handling the PODs agrees.
+var PodNameRegex = DefaultPodNameRegex
+
+// The default PodNameRegex.
+var DefaultPodNameRegex = regexp.MustCompile(`^[A-Za-z_]\w*$`)
+
+// A PodNameRegex which also accepts letters and digits in any script, such as
+// CJK characters or accented letters.  Only characters in the Basic
+// Multilingual Plane are accepted: names are sorted by their UTF-8 bytes here,
+// but by UTF-16 code units in JavaScript, and the two orders only agree when
+// there are no surrogate pairs.
+var UnicodePodNameRegex = regexp.MustCompile(
+	`^[` + bmpClass(unicode.L) + `_][` + bmpClass(unicode.L) + bmpClass(unicode.Nd) + `_]*$`)
+
+// Returns the contents of a regexp character class matching the characters of
+// the given table which are in the Basic Multilingual Plane.
+func bmpClass(table *unicode.RangeTable) string {
+	var b strings.Builder
+	for _, r := range table.R16 {
+		if r.Stride == 1 {
+			fmt.Fprintf(&b, `\x{%x}-\x{%x}`, r.Lo, r.Hi)
+			continue
+		}
+		for c := int(r.Lo); c <= int(r.Hi); c += int(r.Stride) {
+			fmt.Fprintf(&b, `\x{%x}`, c)
+		}
+	}
+	return b.String()
+}
 
 // Checks that the given name is legal for a POD entry.  Returns nil if so.
 func CheckPodName(name string) error {
@@ -48,6 +289,9 @@ func (p *PodEntries) UnmarshalJSON(data []byte) error {
 	if err := json.Unmarshal(data, &deserialized); err != nil {
 		return err
 	}
+	if err := checkDuplicateNames(data); err != nil {
+		return err
+	}
 
 	// Overwrite the output with a new object, to ensure any keys missing
 	// in JSON aren't left over from input.
@@ -61,3 +305,41 @@ func (p *PodEntries) UnmarshalJSON(data []byte) error {
 	// Perform validity checks after unmarshaling.
 	return p.Check()
 }
+
+// Returns an error if a JSON object has the same entry name more than once.
+// The default unmarshaling keeps the last value, while other parsers may keep
+// the first, so a POD with duplicate names could appear to hold different
+// entries depending on who parses it.  The data must already be known to be
+// valid JSON.
+func checkDuplicateNames(data []byte) error {
+	return checkDuplicateKeys(data, "entry name")
+}
+
+// Returns an error if a JSON object has the same key more than once, naming
+// the key with the given description.  Data which isn't an object is accepted.
+func checkDuplicateKeys(data []byte, description string) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return err
+	}
+	seen := make(map[string]bool)
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		name, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("expected %s, got %v", description, token)
+		}
+		if seen[name] {
+			return fmt.Errorf("duplicate %s %q", description, name)
+		}
+		seen[name] = true
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,6 +3,9 @@ package pod
 import (
 	"encoding/json"
 	"math/big"
+	"net/url"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -45,6 +48,39 @@ func TestCheckIllegalPodNames(t *testing.T) {
 	}
 }
 
+func TestUnicodePodNames(t *testing.T) {
+	defer func(original *regexp.Regexp) { PodNameRegex = original }(PodNameRegex)
+
+	if CheckPodName("名前") == nil {
+		t.Fatalf("expected CJK name to be rejected by default")
+	}
+	if _, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{"名前": NewPodStringValue("a")}); err == nil {
+		t.Fatalf("expected POD with CJK name to be rejected by default")
+	}
+
+	PodNameRegex = UnicodePodNameRegex
+	for _, name := range []string{"名前", "_名前2", "café", "a", "abc123_xyz", "\uFF71", "Ωμέγα", "a٣"} {
+		if err := CheckPodName(name); err != nil {
+			t.Fatalf("expected %q to be accepted: %v", name, err)
+		}
+	}
+	// Letters outside the Basic Multilingual Plane would sort differently
+	// here than in JavaScript.
+	for _, name := range []string{"", "2名前", "名 前", "a-b", "\U0001F4A9", "\U00020000", "a\U00020000", "\U0001D400"} {
+		if CheckPodName(name) == nil {
+			t.Fatalf("expected %q to be rejected", name)
+		}
+	}
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{"名前": NewPodStringValue("a")})
+	if err != nil {
+		t.Fatalf("expected POD with CJK name to be created: %v", err)
+	}
+	ok, err := pod.Verify()
+	if err != nil || !ok {
+		t.Fatalf("expected POD with CJK name to verify: %v", err)
+	}
+}
+
 func TestEmptyPodEntries(t *testing.T) {
 	// Empty entries are legal, though nil map is not.
 	entries := PodEntries{}
@@ -151,7 +187,7 @@ func TestIllegalPodEntries(t *testing.T) {
 	if entries.Check() == nil {
 		t.Fatalf("expected bad entries during check")
 	}
-	if _, err := computeContentID(entries); err == nil {
+	if _, err := ComputeContentID(entries); err == nil {
 		t.Fatalf("expected bad entries during compute contentID")
 	}
 	jsonEntries := `{"bad name": null}`
@@ -165,7 +201,7 @@ func TestIllegalPodEntries(t *testing.T) {
 	if entries.Check() == nil {
 		t.Fatalf("expected bad entries during check")
 	}
-	if _, err := computeContentID(entries); err == nil {
+	if _, err := ComputeContentID(entries); err == nil {
 		t.Fatalf("expected bad entries during compute contentID")
 	}
 	jsonEntries = `{"goodName": {"cryptographic": -1 } }`
@@ -178,7 +214,7 @@ func TestIllegalPodEntries(t *testing.T) {
 	if nilEntries.Check() == nil {
 		t.Fatalf("expected bad entries during check")
 	}
-	if _, err := computeContentID(nilEntries); err == nil {
+	if _, err := ComputeContentID(nilEntries); err == nil {
 		t.Fatalf("expected bad entries during compute contentID")
 	}
 }
@@ -197,3 +233,283 @@ func TestBadJSONEntries(t *testing.T) {
 		t.Fatalf("Expected to fail to parse non-entries JSON")
 	}
 }
+
+func TestDuplicateJSONEntries(t *testing.T) {
+	var entries PodEntries
+	for _, data := range []string{
+		`{"a":1,"a":2}`,
+		`{"a":1,"b":{"cryptographic":2},"a":1}`,
+		`{"a":1,"\u0061":2}`,
+	} {
+		if err := json.Unmarshal([]byte(data), &entries); err == nil || !strings.Contains(err.Error(), "duplicate entry name") {
+			t.Fatalf("expected %s to fail with duplicate name, got %v", data, err)
+		}
+	}
+
+	// Names in nested value objects aren't entry names.
+	if err := json.Unmarshal([]byte(`{"a":{"cryptographic":1},"b":{"cryptographic":1}}`), &entries); err != nil {
+		t.Fatalf("expected distinct names to parse: %v", err)
+	}
+
+	// Neither are duplicate keys within a value allowed.
+	for _, data := range []string{
+		`{"a":{"int":1,"int":2}}`,
+		`{"a":{"type":"int","value":1,"value":2}}`,
+	} {
+		if err := json.Unmarshal([]byte(data), &entries); err == nil || !strings.Contains(err.Error(), "duplicate value key") {
+			t.Fatalf("expected %s to fail with duplicate key, got %v", data, err)
+		}
+	}
+
+	var pod Pod
+	if err := json.Unmarshal([]byte(`{"entries":{"a":1,"a":2},"signature":"","signerPublicKey":""}`), &pod); err == nil || !strings.Contains(err.Error(), "duplicate entry name") {
+		t.Fatalf("expected POD with duplicate names to fail, got %v", err)
+	}
+}
+
+func TestCheckMaxEntries(t *testing.T) {
+	entries := PodEntries{
+		"a": NewPodNullValue(),
+		"b": NewPodNullValue(),
+	}
+	if err := entries.CheckMaxEntries(2); err != nil {
+		t.Fatalf("expected entries within limit: %v", err)
+	}
+	err := entries.CheckMaxEntries(1)
+	if err == nil {
+		t.Fatalf("expected entries over limit")
+	}
+	if err.Error() != "POD has 2 entries, more than the maximum of 1" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestEntryLimits(t *testing.T) {
+	defer func(entries, stringBytes, bytesLen int) {
+		MaxEntries, MaxStringBytes, MaxBytesLen = entries, stringBytes, bytesLen
+	}(MaxEntries, MaxStringBytes, MaxBytesLen)
+	MaxEntries, MaxStringBytes, MaxBytesLen = 2, 4, 3
+
+	var entries PodEntries
+	if err := json.Unmarshal([]byte(`{"a":"1234","b":{"bytes":"AQID"}}`), &entries); err != nil {
+		t.Fatalf("expected entries at the limits to parse: %v", err)
+	}
+	for data, expected := range map[string]string{
+		`{"a":1,"b":2,"c":3}`:        "3 entries, more than the maximum of 2",
+		`{"a":"12345"}`:              "string is 5 bytes, more than the maximum of 4",
+		`{"a":"\u00e9\u00e9\u00e9"}`: "string is 6 bytes",
+		`{"b":{"bytes":"AQIDBA"}}`:   "bytes is 4 bytes, more than the maximum of 3",
+	} {
+		if err := json.Unmarshal([]byte(data), &entries); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected %s to fail with %q, got %v", data, expected, err)
+		}
+	}
+
+	MaxEntries, MaxStringBytes, MaxBytesLen = 0, 0, 0
+	if err := json.Unmarshal([]byte(`{"a":"12345","b":{"bytes":"AQIDBA"},"c":3}`), &entries); err != nil {
+		t.Fatalf("expected disabled limits to allow any size: %v", err)
+	}
+}
+
+func TestNullEntriesAffectContentID(t *testing.T) {
+	withNull := PodEntries{
+		"a":     NewPodStringValue("a"),
+		"empty": NewPodNullValue(),
+	}
+	withoutNull := withNull.WithoutNulls()
+	if len(withoutNull) != 1 || withoutNull["a"].StringVal != "a" {
+		t.Fatalf("unexpected entries without nulls: %v", withoutNull)
+	}
+	if len(withNull) != 2 {
+		t.Fatalf("WithoutNulls modified its input")
+	}
+
+	withNullID, err := ComputeContentID(withNull)
+	if err != nil {
+		t.Fatalf("ComputeContentID failed: %v", err)
+	}
+	withoutNullID, err := ComputeContentID(withoutNull)
+	if err != nil {
+		t.Fatalf("ComputeContentID failed: %v", err)
+	}
+	if withNullID.Cmp(withoutNullID) == 0 {
+		t.Fatalf("null entry should change the content ID")
+	}
+
+	normalizedID, err := ComputeContentID(PodEntries{"a": NewPodStringValue("a")}.WithoutNulls())
+	if err != nil {
+		t.Fatalf("ComputeContentID failed: %v", err)
+	}
+	if normalizedID.Cmp(withoutNullID) != 0 {
+		t.Fatalf("entries should have the same content ID once nulls are removed")
+	}
+}
+
+func TestCheckReservedNames(t *testing.T) {
+	reserved := []string{"_seq", "_src_*"}
+
+	entries := PodEntries{
+		"name":     NewPodStringValue("Alice"),
+		"_seq_num": NewPodNullValue(),
+		"_src":     NewPodNullValue(),
+	}
+	if err := entries.CheckReservedNames(reserved); err != nil {
+		t.Fatalf("expected no reserved names: %v", err)
+	}
+	if err := entries.CheckReservedNames(nil); err != nil {
+		t.Fatalf("expected no reserved names without a policy: %v", err)
+	}
+
+	entries = PodEntries{"_seq": NewPodNullValue()}
+	if err := entries.CheckReservedNames(reserved); err == nil {
+		t.Fatalf("expected reserved name to be rejected")
+	}
+	entries = PodEntries{"_src_0": NewPodNullValue()}
+	if err := entries.CheckReservedNames(reserved); err == nil {
+		t.Fatalf("expected reserved prefix to be rejected")
+	}
+}
+
+func TestEntriesFromValues(t *testing.T) {
+	values, err := url.ParseQuery("count=42&neg=-7&ok=true&no=false&when=2025-01-01T00:00:00Z&name=hello+world&mixed=12ab")
+	if err != nil {
+		t.Fatalf("ParseQuery failed: %v", err)
+	}
+	entries, err := EntriesFromValues(values)
+	if err != nil {
+		t.Fatalf("EntriesFromValues failed: %v", err)
+	}
+	expected := PodEntries{
+		"count": PodValue{ValueType: PodIntValue, BigVal: big.NewInt(42)},
+		"neg":   PodValue{ValueType: PodIntValue, BigVal: big.NewInt(-7)},
+		"ok":    PodValue{ValueType: PodBooleanValue, BoolVal: true},
+		"no":    PodValue{ValueType: PodBooleanValue, BoolVal: false},
+		"when":  PodValue{ValueType: PodDateValue, TimeVal: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		"name":  PodValue{ValueType: PodStringValue, StringVal: "hello world"},
+		"mixed": PodValue{ValueType: PodStringValue, StringVal: "12ab"},
+	}
+	if diff := deep.Equal(entries, expected); diff != nil {
+		t.Fatalf("unexpected entries: %v", diff)
+	}
+
+	for _, query := range []string{
+		"a=1&a=2",
+		"bad-name=1",
+		"big=99999999999999999999999999999999999999999999999999",
+	} {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			t.Fatalf("ParseQuery failed: %v", err)
+		}
+		if _, err := EntriesFromValues(values); err == nil {
+			t.Fatalf("expected query %q to fail", query)
+		}
+	}
+}
+
+func TestEntriesGetSet(t *testing.T) {
+	var entries PodEntries
+	if err := entries.Set("count", PodValue{ValueType: PodIntValue, BigVal: big.NewInt(1)}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if value, ok := entries.Get("count"); !ok || value.BigVal.Int64() != 1 {
+		t.Fatalf("expected entry to be set, got %v", value)
+	}
+	if _, ok := entries.Get("missing"); ok {
+		t.Fatalf("expected missing entry")
+	}
+
+	if err := entries.Set("bad name", NewPodStringValue("a")); err == nil {
+		t.Fatalf("expected illegal name to fail")
+	}
+	if err := entries.Set("big", PodValue{ValueType: PodIntValue, BigVal: new(big.Int).Lsh(big.NewInt(1), 64)}); err == nil {
+		t.Fatalf("expected out of range value to fail")
+	}
+	if err := entries.Set("count", PodValue{ValueType: PodIntValue}); err == nil {
+		t.Fatalf("expected nil value to fail")
+	}
+	if len(entries) != 1 || entries["count"].BigVal.Int64() != 1 {
+		t.Fatalf("failed Set should not change entries: %v", entries)
+	}
+}
+
+func TestEntriesClone(t *testing.T) {
+	original := PodEntries{
+		"count": PodValue{ValueType: PodIntValue, BigVal: big.NewInt(1)},
+		"data":  PodValue{ValueType: PodBytesValue, BytesVal: []byte{1, 2, 3}},
+		"name":  NewPodStringValue("Alice"),
+	}
+	clone := original.Clone()
+	if diff := deep.Equal(clone, original); diff != nil {
+		t.Fatalf("clone differs from original: %v", diff)
+	}
+
+	clone["count"].BigVal.SetInt64(2)
+	clone["data"].BytesVal[0] = 9
+	clone["name"] = NewPodStringValue("Mallory")
+	delete(clone, "data")
+	if original["count"].BigVal.Int64() != 1 || original["data"].BytesVal[0] != 1 ||
+		original["name"].StringVal != "Alice" || len(original) != 3 {
+		t.Fatalf("modifying clone changed original: %v", original)
+	}
+
+	if PodEntries(nil).Clone() != nil {
+		t.Fatalf("expected nil clone of nil entries")
+	}
+}
+
+func TestEntriesMap(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	big128 := new(big.Int).Lsh(big.NewInt(1), 128)
+	m := map[string]interface{}{
+		"s": "hello",
+		"b": true,
+		"i": int64(-5),
+		"c": big128,
+		"y": []byte{1, 2, 3},
+		"d": date,
+		"n": nil,
+	}
+	entries, err := PodEntriesFromMap(m)
+	if err != nil {
+		t.Fatalf("PodEntriesFromMap failed: %v", err)
+	}
+	expected := PodEntries{
+		"s": NewPodStringValue("hello"),
+		"b": NewPodBooleanValue(true),
+		"i": PodValue{ValueType: PodIntValue, BigVal: big.NewInt(-5)},
+		"c": PodValue{ValueType: PodCryptographicValue, BigVal: big128},
+		"y": PodValue{ValueType: PodBytesValue, BytesVal: []byte{1, 2, 3}},
+		"d": PodValue{ValueType: PodDateValue, TimeVal: date},
+		"n": NewPodNullValue(),
+	}
+	if diff := deep.Equal(entries, expected); diff != nil {
+		t.Fatalf("unexpected entries: %v", diff)
+	}
+	if diff := deep.Equal(entries.ToMap(), m); diff != nil {
+		t.Fatalf("ToMap didn't round trip: %v", diff)
+	}
+
+	if _, err := PodEntriesFromMap(map[string]interface{}{"f": 1.5}); err == nil || !strings.Contains(err.Error(), "f: unsupported type") {
+		t.Fatalf("expected unsupported type error, got %v", err)
+	}
+	if _, err := PodEntriesFromMap(map[string]interface{}{"bad name": 1}); err == nil {
+		t.Fatalf("expected invalid name to fail")
+	}
+}
+
+func TestEntriesDump(t *testing.T) {
+	entries := PodEntries{
+		"b": NewPodStringValue("321"),
+		"A": PodValue{ValueType: PodIntValue, BigVal: big.NewInt(321)},
+		"c": PodValue{ValueType: PodCryptographicValue, BigVal: big.NewInt(321)},
+		"n": NewPodNullValue(),
+	}
+	expected := "A: int(321)\nb: string(\"321\")\nc: cryptographic(321)\nn: null\n"
+	if dump := entries.Dump(); dump != expected {
+		t.Fatalf("unexpected dump:\n%s\nexpected:\n%s", dump, expected)
+	}
+	if dump := (PodEntries{}).Dump(); dump != "" {
+		t.Fatalf("expected empty dump, got %q", dump)
+	}
+}
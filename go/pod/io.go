@@ -0,0 +1,196 @@
+package pod
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Outcome of reading and verifying a single POD.  Valid is true only if the
+// POD parsed and its signature verified.  Err is set if the POD could not be
+// read, parsed, or checked.
+type VerifyResult struct {
+	Pod   *Pod
+	Valid bool
+	Err   error
+}
+
+// Read every *.json file in the given directory as a POD and verify it, using
+// up to the given number of concurrent workers.  Results are keyed by file
+// name.  Files which can't be read or parsed produce failed results rather
+// than stopping the walk.  An error is returned only if the directory itself
+// can't be read.
+func VerifyDir(path string, workers int) (map[string]VerifyResult, error) {
+	dirEntries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read POD directory: %w", err)
+	}
+	var files []string
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() && filepath.Ext(dirEntry.Name()) == ".json" {
+			files = append(files, filepath.Join(path, dirEntry.Name()))
+		}
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	results := make(map[string]VerifyResult, len(files))
+	var resultsMutex sync.Mutex
+	var wg sync.WaitGroup
+	fileChan := make(chan string)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range fileChan {
+				result := verifyFile(file)
+				resultsMutex.Lock()
+				results[filepath.Base(file)] = result
+				resultsMutex.Unlock()
+			}
+		}()
+	}
+	for _, file := range files {
+		fileChan <- file
+	}
+	close(fileChan)
+	wg.Wait()
+
+	return results, nil
+}
+
+func verifyFile(file string) VerifyResult {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return VerifyResult{Err: fmt.Errorf("failed to read POD file: %w", err)}
+	}
+	return parseAndVerify(data)
+}
+
+// Parse a POD from JSON and verify it.
+func parseAndVerify(data []byte) VerifyResult {
+	var pod Pod
+	if err := json.Unmarshal(data, &pod); err != nil {
+		return VerifyResult{Err: fmt.Errorf("failed to parse POD: %w", err)}
+	}
+	ok, err := pod.Verify()
+	return VerifyResult{Pod: &pod, Valid: ok && err == nil, Err: err}
+}
+
+// Verify a POD stored as separate files: its entries as JSON, and its signature
+// and signer public key each as hex or Base64 text.  Surrounding whitespace in
+// the signature and public key files is ignored.
+func VerifyFiles(entriesPath string, signaturePath string, publicKeyPath string) (bool, error) {
+	entriesData, err := os.ReadFile(entriesPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read entries file: %w", err)
+	}
+	var entries PodEntries
+	if err := json.Unmarshal(entriesData, &entries); err != nil {
+		return false, fmt.Errorf("failed to parse entries file: %w", err)
+	}
+	signature, err := os.ReadFile(signaturePath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read signature file: %w", err)
+	}
+	publicKey, err := os.ReadFile(publicKeyPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read public key file: %w", err)
+	}
+
+	pod := &Pod{
+		Entries:         entries,
+		Signature:       strings.TrimSpace(string(signature)),
+		SignerPublicKey: strings.TrimSpace(string(publicKey)),
+	}
+	if err := pod.CheckFormat(); err != nil {
+		return false, err
+	}
+	return pod.Verify()
+}
+
+// Read a single POD as JSON from r, checking that it's well-formed.  Anything
+// other than whitespace after the POD is an error.  The signature is not
+// verified.
+func DecodePod(r io.Reader) (*Pod, error) {
+	decoder := json.NewDecoder(r)
+	var pod Pod
+	if err := decoder.Decode(&pod); err != nil {
+		return nil, fmt.Errorf("failed to parse POD: %w", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after POD")
+	}
+	return &pod, nil
+}
+
+// Write a POD to w as JSON followed by a newline, after checking that it's
+// well-formed.  The signature is not verified.
+func EncodePod(w io.Writer, p *Pod) error {
+	if p == nil {
+		return fmt.Errorf("POD should not be nil")
+	}
+	if err := p.CheckFormat(); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(p)
+}
+
+// A POD read from a stream was malformed.  The stream itself is still intact,
+// so reading can continue with the next POD.
+var ErrMalformedPod = errors.New("malformed POD")
+
+// Reads a sequence of JSON PODs from a stream such as a network connection,
+// one at a time.  PODs may be separated by whitespace or nothing at all, and
+// may arrive split across any number of reads.
+type PodConnReader struct {
+	// If true, each POD's signature is verified before it's returned.
+	Verify bool
+
+	decoder *json.Decoder
+	err     error
+}
+
+// Create a reader for PODs arriving on conn.
+func NewPodConnReader(conn io.Reader) *PodConnReader {
+	return &PodConnReader{decoder: json.NewDecoder(conn)}
+}
+
+// Reads the next POD from the stream.  The returned bool is true if the POD is
+// well-formed and, if Verify is set, its signature is valid.
+//
+// A POD which isn't well-formed produces an error wrapping ErrMalformedPod,
+// after which Next can be called again to read the following POD.  Any other
+// error, including io.EOF at the end of the stream, means the stream can't be
+// read further, and is returned again by all later calls.
+func (r *PodConnReader) Next() (*Pod, bool, error) {
+	if r.err != nil {
+		return nil, false, r.err
+	}
+
+	var raw json.RawMessage
+	if err := r.decoder.Decode(&raw); err != nil {
+		r.err = err
+		return nil, false, err
+	}
+
+	var pod Pod
+	if err := json.Unmarshal(raw, &pod); err != nil {
+		return nil, false, fmt.Errorf("%w: %w", ErrMalformedPod, err)
+	}
+	if !r.Verify {
+		return &pod, true, nil
+	}
+	ok, err := pod.Verify()
+	if err != nil {
+		return &pod, false, fmt.Errorf("%w: %w", ErrMalformedPod, err)
+	}
+	return &pod, ok, nil
+}
@@ -0,0 +1,198 @@
+package pod
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestVerifyDir(t *testing.T) {
+	dir := t.TempDir()
+
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	validJSON, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatalf("Failed to marshal pod to JSON: %v", err)
+	}
+	pod.Entries["message"] = NewPodStringValue("tampered")
+	tamperedJSON, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatalf("Failed to marshal pod to JSON: %v", err)
+	}
+
+	files := map[string][]byte{
+		"valid.json":     validJSON,
+		"tampered.json":  tamperedJSON,
+		"malformed.json": []byte("{not json"),
+		"ignored.txt":    validJSON,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+	}
+
+	results, err := VerifyDir(dir, 2)
+	if err != nil {
+		t.Fatalf("VerifyDir failed: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+	if result := results["valid.json"]; !result.Valid || result.Err != nil || result.Pod == nil {
+		t.Fatalf("expected valid POD to verify: %+v", result)
+	}
+	if result := results["tampered.json"]; result.Valid || result.Err != nil {
+		t.Fatalf("expected tampered POD to fail verification: %+v", result)
+	}
+	if result := results["malformed.json"]; result.Valid || result.Err == nil {
+		t.Fatalf("expected malformed POD to fail parsing: %+v", result)
+	}
+
+	if _, err := VerifyDir(filepath.Join(dir, "missing"), 2); err == nil {
+		t.Fatalf("expected missing directory to fail")
+	}
+}
+
+func TestVerifyFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	entriesJSON, err := json.Marshal(pod.Entries)
+	if err != nil {
+		t.Fatalf("Failed to marshal entries to JSON: %v", err)
+	}
+	signatureBytes, err := DecodeBytes(pod.Signature, 64)
+	if err != nil {
+		t.Fatalf("DecodeBytes failed: %v", err)
+	}
+	files := map[string]string{
+		"entries.json":  string(entriesJSON),
+		"tampered.json": `{"message":"tampered"}`,
+		"signature.hex": hex.EncodeToString(signatureBytes) + "\n",
+		"pubkey.b64":    pod.SignerPublicKey + "\n",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	ok, err := VerifyFiles(path("entries.json"), path("signature.hex"), path("pubkey.b64"))
+	if err != nil || !ok {
+		t.Fatalf("expected files to verify: %v", err)
+	}
+	ok, err = VerifyFiles(path("tampered.json"), path("signature.hex"), path("pubkey.b64"))
+	if err != nil || ok {
+		t.Fatalf("expected tampered entries to fail: %v", err)
+	}
+	if _, err := VerifyFiles(path("entries.json"), path("missing"), path("pubkey.b64")); err == nil {
+		t.Fatalf("expected missing signature file to fail")
+	}
+	if _, err := VerifyFiles(path("entries.json"), path("pubkey.b64"), path("pubkey.b64")); err == nil {
+		t.Fatalf("expected malformed signature to fail")
+	}
+}
+
+func TestEncodeDecodePod(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := EncodePod(&buf, pod); err != nil {
+		t.Fatalf("EncodePod failed: %v", err)
+	}
+	encoded := buf.String()
+	decoded, err := DecodePod(strings.NewReader(encoded + "  \n"))
+	if err != nil {
+		t.Fatalf("DecodePod failed: %v", err)
+	}
+	ok, err := decoded.Verify()
+	if err != nil || !ok {
+		t.Fatalf("expected decoded POD to verify: %v", err)
+	}
+
+	for _, data := range []string{encoded + "x", encoded + encoded, encoded[:len(encoded)-3], "", `{"entries":{}}`} {
+		if _, err := DecodePod(strings.NewReader(data)); err == nil {
+			t.Fatalf("expected %q to fail to decode", data)
+		}
+	}
+
+	bad := *pod
+	bad.Signature = "bad"
+	buf.Reset()
+	if err := EncodePod(&buf, &bad); err == nil || buf.Len() != 0 {
+		t.Fatalf("expected malformed POD to fail to encode without writing")
+	}
+}
+
+func TestPodConnReader(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	validJSON, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatalf("Failed to marshal pod to JSON: %v", err)
+	}
+	pod.Entries["message"] = NewPodStringValue("tampered")
+	tamperedJSON, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatalf("Failed to marshal pod to JSON: %v", err)
+	}
+
+	// Deliver the stream one byte at a time to exercise partial reads.
+	stream := string(validJSON) + "\n" + `{"entries":{"bad name":1}}` + string(tamperedJSON) + "\n  " + string(validJSON)
+	reader := NewPodConnReader(iotest.OneByteReader(strings.NewReader(stream)))
+	reader.Verify = true
+
+	if p, ok, err := reader.Next(); err != nil || !ok || p == nil {
+		t.Fatalf("expected valid POD: %v %v", ok, err)
+	}
+	if _, ok, err := reader.Next(); !errors.Is(err, ErrMalformedPod) || ok {
+		t.Fatalf("expected malformed POD error: %v %v", ok, err)
+	}
+	if p, ok, err := reader.Next(); err != nil || ok || p == nil {
+		t.Fatalf("expected tampered POD to fail verification: %v %v", ok, err)
+	}
+	if p, ok, err := reader.Next(); err != nil || !ok || p == nil {
+		t.Fatalf("expected valid POD: %v %v", ok, err)
+	}
+	if _, _, err := reader.Next(); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+
+	// Broken JSON ends the stream.
+	reader = NewPodConnReader(strings.NewReader(`{"entries":` + "]" + string(validJSON)))
+	_, _, err = reader.Next()
+	if err == nil || errors.Is(err, ErrMalformedPod) {
+		t.Fatalf("expected stream error, got %v", err)
+	}
+	if _, _, err2 := reader.Next(); err2 != err {
+		t.Fatalf("expected stream error to repeat, got %v", err2)
+	}
+}
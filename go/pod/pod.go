@@ -1,8 +1,15 @@
 package pod
 
 import (
+	"bytes"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math/big"
+	"sync"
+
+	"github.com/iden3/go-iden3-crypto/v2/utils"
 )
 
 // Provable Object Datatype containing a cryptographically verified key/value store
@@ -29,13 +36,234 @@ func (p *Pod) checkFormatWithoutEntries() error {
 	if !SignatureRegex.MatchString(p.Signature) {
 		return fmt.Errorf("POD signature does not match expected format - 64 bytes Base64 or hex: '%s'", p.Signature)
 	}
-	if !SignatureRegex.MatchString(p.Signature) {
-		return fmt.Errorf("POD signature does not match expected format - 64 bytes Base64 or hex: '%s'", p.Signature)
+	if !PublicKeyRegex.MatchString(p.SignerPublicKey) {
+		return fmt.Errorf("POD signer public key does not match expected format - 32 bytes Base64 or hex: '%s'", p.SignerPublicKey)
 	}
 	return nil
 }
 
-// Parse a POD from JSON in POD's terse human-readable format
+// Rewrites the signature and signer public key of this POD in the canonical
+// unpadded Base64 encoding, if they were encoded in any of the other formats
+// accepted by Verify().  Returns whether either field changed.
+func (p *Pod) Normalize() (changed bool, err error) {
+	signatureBytes, err := DecodeBytes(p.Signature, 64)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode signature: %w", err)
+	}
+	publicKeyBytes, err := DecodeBytes(p.SignerPublicKey, 32)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode signer public key: %w", err)
+	}
+
+	signature := noPadB64.EncodeToString(signatureBytes)
+	publicKey := noPadB64.EncodeToString(publicKeyBytes)
+	changed = signature != p.Signature || publicKey != p.SignerPublicKey
+	p.Signature = signature
+	p.SignerPublicKey = publicKey
+	return changed, nil
+}
+
+type displayEntry struct {
+	Name  string       `json:"name"`
+	Type  PodValueType `json:"type"`
+	Value interface{}  `json:"value"`
+}
+
+// Returns the entries of this POD as a JSON array of objects with "name",
+// "type", and "value" fields, sorted by name.  This is a display format for
+// user interfaces, which can't be parsed back into a POD.  The signature is
+// not verified.
+func (p *Pod) DisplayJSON() ([]byte, error) {
+	if err := p.Entries.Check(); err != nil {
+		return nil, err
+	}
+	display := make([]displayEntry, 0, len(p.Entries))
+	for _, name := range p.Entries.sortedNames() {
+		value := p.Entries[name]
+		display = append(display, displayEntry{
+			Name:  name,
+			Type:  value.ValueType,
+			Value: value.displayValue(),
+		})
+	}
+	return json.Marshal(display)
+}
+
+// Returns a string which identifies this POD for use as a map key, in the form
+// "contentID:signerPublicKey:signature" with each part in lowercase hex.  PODs
+// with the same entries, signer, and signature have the same key regardless
+// of how their signature and public key are encoded.  The signature is not
+// verified.
+func (p *Pod) MapKey() (string, error) {
+	contentID, err := ComputeContentID(p.Entries)
+	if err != nil {
+		return "", fmt.Errorf("failed computing content ID: %w", err)
+	}
+	publicKeyBytes, err := DecodeBytes(p.SignerPublicKey, 32)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode signer public key: %w", err)
+	}
+	signatureBytes, err := DecodeBytes(p.Signature, 64)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode signature: %w", err)
+	}
+	return fmt.Sprintf("%064x:%s:%s", contentID, hex.EncodeToString(publicKeyBytes), hex.EncodeToString(signatureBytes)), nil
+}
+
+// Checks whether two PODs have the same entries, signer, and signature, after
+// decoding, so differences in encoding such as hex vs. Base64 or the
+// representation of entry values are ignored.  The signatures are not
+// verified.
+func (p *Pod) SemanticEqual(other *Pod) (bool, error) {
+	key, err := p.MapKey()
+	if err != nil {
+		return false, err
+	}
+	otherKey, err := other.MapKey()
+	if err != nil {
+		return false, err
+	}
+	return key == otherKey, nil
+}
+
+// Parses two PODs from JSON and checks whether they're equal according to
+// SemanticEqual, ignoring whitespace and encoding differences.
+func PodJSONEqual(a []byte, b []byte) (bool, error) {
+	var podA, podB Pod
+	if err := json.Unmarshal(a, &podA); err != nil {
+		return false, fmt.Errorf("failed to parse first POD: %w", err)
+	}
+	if err := json.Unmarshal(b, &podB); err != nil {
+		return false, fmt.Errorf("failed to parse second POD: %w", err)
+	}
+	return podA.SemanticEqual(&podB)
+}
+
+// Removes duplicates from a batch of PODs, treating PODs as the same if they
+// have the same MapKey, regardless of encoding.  Returns the first of each
+// group in its original order, with its signature and public key normalized.
+// The returned PODs are copies, though they share entries with the input.  The
+// signatures are not verified.
+func DedupePods(pods []*Pod) ([]*Pod, error) {
+	seen := make(map[string]bool, len(pods))
+	var unique []*Pod
+	for i, p := range pods {
+		key, err := p.MapKey()
+		if err != nil {
+			return nil, fmt.Errorf("POD %d: %w", i, err)
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized := *p
+		if _, err := normalized.Normalize(); err != nil {
+			return nil, fmt.Errorf("POD %d: %w", i, err)
+		}
+		unique = append(unique, &normalized)
+	}
+	return unique, nil
+}
+
+// Returns the signer public key of this POD as an eddsa_pubkey value, encoded
+// in canonical unpadded Base64.  The signature is not verified.
+func (p *Pod) SignerValue() (PodValue, error) {
+	publicKeyBytes, err := DecodeBytes(p.SignerPublicKey, 32)
+	if err != nil {
+		return PodValue{}, fmt.Errorf("failed to decode signer public key: %w", err)
+	}
+	return NewPodEdDSAPubkeyValue(noPadB64.EncodeToString(publicKeyBytes))
+}
+
+// Returns the content ID of this POD, which is the root of the Merkle tree of
+// its entries, and the message signed by its signature.  The signature is not
+// verified.
+func (p *Pod) ContentID() (*big.Int, error) {
+	contentID, err := ComputeContentID(p.Entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed computing content ID: %w", err)
+	}
+	return contentID, nil
+}
+
+// Returns the content ID of this POD as 0x-prefixed hex, zero-padded to 32
+// bytes.  The signature is not verified.
+func (p *Pod) ContentIDHex() (string, error) {
+	contentID, err := p.ContentID()
+	if err != nil {
+		return "", err
+	}
+	return formatContentID(contentID), nil
+}
+
+// Returns the exact message signed by this POD's signature: the content ID
+// field element encoded as 32 bytes in little-endian order.  This is the
+// BabyJubJub convention used by SignPoseidon when deriving its nonce, and by
+// the compressed signature and key encodings.  Entries are checked, but the
+// signature is not verified.
+func (p *Pod) SignedMessage() ([]byte, error) {
+	contentID, err := ComputeContentID(p.Entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed computing content ID: %w", err)
+	}
+	message := utils.BigIntLEBytes(contentID)
+	return message[:], nil
+}
+
+// Options for serializing a POD to JSON.  The zero value produces the same
+// terse format as json.Marshal.
+type MarshalOptions struct {
+	// Encode every value in the legacy format with separate type and value
+	// fields, e.g. {"type":"int","value":321}, for consumers which don't
+	// understand the terse format.
+	Legacy bool
+
+	// Encode the signature and signer public key as lowercase hex rather than
+	// as they're stored, for consumers which don't accept Base64.
+	HexCrypto bool
+}
+
+// Serialize this POD to JSON with the given options.  Unless HexCrypto is set,
+// the signature and public key are unchanged.
+func (p *Pod) MarshalJSONWithOptions(opts MarshalOptions) ([]byte, error) {
+	signature, publicKey := p.Signature, p.SignerPublicKey
+	if opts.HexCrypto {
+		signatureBytes, err := DecodeBytes(p.Signature, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode signature: %w", err)
+		}
+		publicKeyBytes, err := DecodeBytes(p.SignerPublicKey, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode signer public key: %w", err)
+		}
+		signature = hex.EncodeToString(signatureBytes)
+		publicKey = hex.EncodeToString(publicKeyBytes)
+	}
+
+	entries := make(map[string]json.RawMessage, len(p.Entries))
+	for name, value := range p.Entries {
+		var data []byte
+		var err error
+		if opts.Legacy {
+			data, err = value.marshalLegacyJSON()
+		} else {
+			data, err = value.MarshalJSON()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		entries[name] = data
+	}
+	return json.Marshal(struct {
+		Entries         map[string]json.RawMessage `json:"entries"`
+		Signature       string                     `json:"signature"`
+		SignerPublicKey string                     `json:"signerPublicKey"`
+	}{entries, signature, publicKey})
+}
+
+// Parse a POD from JSON in POD's terse human-readable format.  The signature
+// and public key may be hex or Base64, and are stored in canonical unpadded
+// Base64.
 func (p *Pod) UnmarshalJSON(data []byte) error {
 	// Use the default unmarshal behavior, using a typecast to avoid
 	// recursing back into this customized unmarshaler.
@@ -51,5 +279,91 @@ func (p *Pod) UnmarshalJSON(data []byte) error {
 
 	// Perform validity checks after unmarshaling. Entries are already checked
 	// by their own unmarshaling.
-	return p.checkFormatWithoutEntries()
+	if err := p.checkFormatWithoutEntries(); err != nil {
+		return err
+	}
+
+	// Store the signature and public key in canonical form, so that a POD
+	// serializes the same way regardless of how it was encoded.
+	_, err := p.Normalize()
+	return err
+}
+
+// Parse a POD from JSON like json.Unmarshal, but reject any top-level field
+// other than "entries", "signature", and "signerPublicKey", such as the fields
+// of a wrapper object, and any data after the POD.  The signature and public
+// key are normalized as by UnmarshalJSON, but the signature is not verified.
+func UnmarshalPodStrict(data []byte) (*Pod, error) {
+	// The custom unmarshaler would ignore DisallowUnknownFields, so decode
+	// with the default behavior and check the format afterward.
+	type podWithoutUnmarshal Pod
+	var deserialized podWithoutUnmarshal
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&deserialized); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after POD")
+	}
+
+	pod := (*Pod)(&deserialized)
+	if err := pod.checkFormatWithoutEntries(); err != nil {
+		return nil, err
+	}
+	if _, err := pod.Normalize(); err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+// A POD which is verified only when its entries are first read, so that PODs
+// which are discarded unread are never verified.  The result of verification is
+// remembered, so a LazyPod is verified at most once.  Safe for concurrent use.
+//
+// The wrapped POD must not be modified after it's wrapped.
+type LazyPod struct {
+	pod  *Pod
+	once sync.Once
+	err  error
+}
+
+// Wrap a POD to be verified on first access.
+func NewLazyPod(p *Pod) *LazyPod {
+	return &LazyPod{pod: p}
+}
+
+func (l *LazyPod) verify() error {
+	l.once.Do(func() {
+		ok, err := l.pod.Verify()
+		if err != nil {
+			l.err = err
+		} else if !ok {
+			l.err = ErrSignatureMismatch
+		}
+	})
+	return l.err
+}
+
+// Returns the entries of the POD, verifying it first if that hasn't been done.
+// Returns an error if the POD isn't valid.
+func (l *LazyPod) Entries() (PodEntries, error) {
+	if err := l.verify(); err != nil {
+		return nil, err
+	}
+	return l.pod.Entries, nil
+}
+
+// Returns the named entry of the POD, verifying it first if that hasn't been
+// done.  Returns an error if the POD isn't valid, or an error wrapping
+// ErrMissingEntry if the entry doesn't exist.
+func (l *LazyPod) Get(name string) (PodValue, error) {
+	if err := l.verify(); err != nil {
+		return PodValue{}, err
+	}
+	value, ok := l.pod.Entries[name]
+	if !ok {
+		return PodValue{}, fmt.Errorf("%w: %q", ErrMissingEntry, name)
+	}
+	return value, nil
 }
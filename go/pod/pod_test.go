@@ -1,14 +1,20 @@
 package pod
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"io"
 	"math/big"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/iden3/go-iden3-crypto/v2/babyjub"
+	"github.com/iden3/go-iden3-crypto/v2/poseidon"
 )
 
 func TestCreateGoPod(t *testing.T) {
@@ -148,8 +154,9 @@ func TestPodMarshal(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to marshal pod to JSON: %v", err)
 	}
-	// We expect the serialized pod to be in the newest format, not the legacy format
-	expectedPod := `{"entries":{"created_by":"Golang","year":2025},"signature":"d9731fa454723273ab8e03bd26af925beb387321336827903af2df0b67d7d29bfa20cb5fee4d466fa718428b49f4cb4ba5e065b8409e5df2266f85aedf072d05","signerPublicKey":"56ca90f80d7c374ae7485e9bcc47d4ac399460948da6aeeb899311097925a72c"}`
+	// We expect the serialized pod to be in the newest format, not the legacy
+	// format, with the hex signature and public key normalized to Base64
+	expectedPod := `{"entries":{"created_by":"Golang","year":2025},"signature":"2XMfpFRyMnOrjgO9Jq+SW+s4cyEzaCeQOvLfC2fX0pv6IMtf7k1Gb6cYQotJ9MtLpeBluECeXfImb4Wu3wctBQ","signerPublicKey":"VsqQ+A18N0rnSF6bzEfUrDmUYJSNpq7riZMRCXklpyw"}`
 	if string(serializedPod) != expectedPod {
 		t.Fatalf("UnmarshalPod returned invalid pod: %v", string(serializedPod))
 	}
@@ -196,8 +203,9 @@ func TestPodMarshal(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to marshal pod to JSON: %v", err)
 	}
-	// We expect the serialized pod to be in the newest format, not the legacy format
-	expectedPod = `{"entries":{"A":123,"B":321,"C":false,"D":"foobar","G":-7},"signature":"fd75dc76f55eeb27e518ed5ebaca78a2b269e27d70cc0106b9f1e823380995ad8a2216351493ba3f50704ef3daae86b5163d6055d0c6644c4a1e64f03adc2704","signerPublicKey":"c433f7a696b7aa3a5224efb3993baf0ccd9e92eecee0c29a3f6c8208a9e81d9e"}`
+	// We expect the serialized pod to be in the newest format, not the legacy
+	// format, with the hex signature and public key normalized to Base64
+	expectedPod = `{"entries":{"A":123,"B":321,"C":false,"D":"foobar","G":-7},"signature":"/XXcdvVe6yflGO1eusp4orJp4n1wzAEGufHoIzgJla2KIhY1FJO6P1BwTvParoa1Fj1gVdDGZExKHmTwOtwnBA","signerPublicKey":"xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4"}`
 	if string(serializedPod) != expectedPod {
 		t.Fatalf("UnmarshalPod returned invalid pod: %v", string(serializedPod))
 	}
@@ -405,6 +413,145 @@ func TestJSONPodCompatibility(t *testing.T) {
 	}
 }
 
+func TestMarshalLegacyJSON(t *testing.T) {
+	// The inverse of legacy format #1 in TestPodMarshal.
+	typedJSONPod := `{"entries":{"created_by":{"type":"string","value":"Golang"},"year":{"type":"int","value":2025}},"signature":"d9731fa454723273ab8e03bd26af925beb387321336827903af2df0b67d7d29bfa20cb5fee4d466fa718428b49f4cb4ba5e065b8409e5df2266f85aedf072d05","signerPublicKey":"56ca90f80d7c374ae7485e9bcc47d4ac399460948da6aeeb899311097925a72c"}`
+	var pod Pod
+	if err := json.Unmarshal([]byte(typedJSONPod), &pod); err != nil {
+		t.Fatalf("Failed to unmarshal legacy pod from JSON: %v", err)
+	}
+	serializedPod, err := pod.MarshalJSONWithOptions(MarshalOptions{Legacy: true, HexCrypto: true})
+	if err != nil {
+		t.Fatalf("MarshalJSONWithOptions failed: %v", err)
+	}
+	if string(serializedPod) != typedJSONPod {
+		t.Fatalf("unexpected legacy JSON: %s", serializedPod)
+	}
+	terse, err := json.Marshal(&pod)
+	if err != nil {
+		t.Fatalf("Failed to marshal pod to JSON: %v", err)
+	}
+	serializedPod, err = pod.MarshalJSONWithOptions(MarshalOptions{})
+	if err != nil || string(serializedPod) != string(terse) {
+		t.Fatalf("expected default options to match json.Marshal, got %s: %v", serializedPod, err)
+	}
+
+	// Every type round trips through the legacy format.
+	signed, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"s": NewPodStringValue("hello"),
+		"b": NewPodBooleanValue(true),
+		"i": PodValue{ValueType: PodIntValue, BigVal: big.NewInt(9007199254740992)},
+		"c": PodValue{ValueType: PodCryptographicValue, BigVal: PodCryptographicMax()},
+		"y": PodValue{ValueType: PodBytesValue, BytesVal: []byte{1, 2, 3}},
+		"d": PodValue{ValueType: PodDateValue, TimeVal: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		"p": PodValue{ValueType: PodEdDSAPubkeyValue, StringVal: "xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4"},
+		"n": NewPodNullValue(),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	serializedPod, err = signed.MarshalJSONWithOptions(MarshalOptions{Legacy: true})
+	if err != nil {
+		t.Fatalf("MarshalJSONWithOptions failed: %v", err)
+	}
+	var parsed Pod
+	if err := json.Unmarshal(serializedPod, &parsed); err != nil {
+		t.Fatalf("Failed to unmarshal legacy JSON %s: %v", serializedPod, err)
+	}
+	ok, err := parsed.Verify()
+	if err != nil || !ok {
+		t.Fatalf("expected legacy JSON to verify: %v", err)
+	}
+}
+
+func TestUnmarshalNormalizesSignature(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"A": PodValue{ValueType: PodIntValue, BigVal: big.NewInt(123)},
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	canonical, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatalf("Failed to marshal pod to JSON: %v", err)
+	}
+	hexJSON, err := pod.MarshalJSONWithOptions(MarshalOptions{HexCrypto: true})
+	if err != nil {
+		t.Fatalf("MarshalJSONWithOptions failed: %v", err)
+	}
+	padded := *pod
+	padded.Signature += "=="
+	padded.SignerPublicKey += "="
+	paddedJSON, err := json.Marshal(&padded)
+	if err != nil {
+		t.Fatalf("Failed to marshal pod to JSON: %v", err)
+	}
+
+	for _, data := range [][]byte{canonical, hexJSON, paddedJSON} {
+		var parsed Pod
+		if err := json.Unmarshal(data, &parsed); err != nil {
+			t.Fatalf("Failed to unmarshal %s: %v", data, err)
+		}
+		if parsed.Signature != pod.Signature || parsed.SignerPublicKey != pod.SignerPublicKey {
+			t.Fatalf("expected %s to be normalized, got %v", data, parsed)
+		}
+		serialized, err := json.Marshal(&parsed)
+		if err != nil || string(serialized) != string(canonical) {
+			t.Fatalf("expected %s to serialize canonically, got %s: %v", data, serialized, err)
+		}
+		strict, err := UnmarshalPodStrict(data)
+		if err != nil || strict.Signature != pod.Signature || strict.SignerPublicKey != pod.SignerPublicKey {
+			t.Fatalf("expected strict parsing of %s to be normalized, got %v: %v", data, strict, err)
+		}
+	}
+}
+
+func TestMarshalHexCrypto(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"A": PodValue{ValueType: PodIntValue, BigVal: big.NewInt(123)},
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	serializedPod, err := pod.MarshalJSONWithOptions(MarshalOptions{HexCrypto: true})
+	if err != nil {
+		t.Fatalf("MarshalJSONWithOptions failed: %v", err)
+	}
+	signatureBytes, err := DecodeBytes(pod.Signature, 64)
+	if err != nil {
+		t.Fatalf("DecodeBytes failed: %v", err)
+	}
+	hexSignature := hex.EncodeToString(signatureBytes)
+	expectedPod := `{"entries":{"A":123},"signature":"` + hexSignature + `","signerPublicKey":"c433f7a696b7aa3a5224efb3993baf0ccd9e92eecee0c29a3f6c8208a9e81d9e"}`
+	if string(serializedPod) != expectedPod {
+		t.Fatalf("unexpected hex JSON %s", serializedPod)
+	}
+	var parsed Pod
+	if err := json.Unmarshal(serializedPod, &parsed); err != nil {
+		t.Fatalf("Failed to unmarshal hex JSON %s: %v", serializedPod, err)
+	}
+	ok, err := parsed.Verify()
+	if err != nil || !ok {
+		t.Fatalf("expected hex JSON to verify: %v", err)
+	}
+
+	// Both options can be combined, and the default remains Base64.
+	serializedPod, err = pod.MarshalJSONWithOptions(MarshalOptions{Legacy: true, HexCrypto: true})
+	if err != nil || !strings.Contains(string(serializedPod), `{"type":"int","value":123}`) || !strings.Contains(string(serializedPod), hexSignature) {
+		t.Fatalf("unexpected legacy hex JSON %s: %v", serializedPod, err)
+	}
+	serializedPod, err = json.Marshal(pod)
+	if err != nil || !strings.Contains(string(serializedPod), pod.Signature) {
+		t.Fatalf("expected default JSON to keep Base64 signature, got %s: %v", serializedPod, err)
+	}
+
+	bad := *pod
+	bad.Signature = "bad"
+	if _, err := bad.MarshalJSONWithOptions(MarshalOptions{HexCrypto: true}); err == nil {
+		t.Fatalf("expected malformed signature to fail")
+	}
+}
+
 func TestBadJSONPod(t *testing.T) {
 	// This utest grew out of an actual bug. The input string is a PODPCD of the
 	// POD used in the compatibility test.  The result is that none of the JSON
@@ -433,3 +580,1215 @@ func TestBadJSONPod(t *testing.T) {
 		t.Fatalf("Expected to fail to parse non-POD JSON")
 	}
 }
+
+func TestUnmarshalPodStrict(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"A": NewPodStringValue("a"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	data, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatalf("failed to marshal POD: %v", err)
+	}
+	parsed, err := UnmarshalPodStrict(data)
+	if err != nil {
+		t.Fatalf("UnmarshalPodStrict failed: %v", err)
+	}
+	ok, err := parsed.Verify()
+	if err != nil || !ok {
+		t.Fatalf("expected parsed POD to verify: %v", err)
+	}
+
+	wrapped := `{"id":"8209fd10-667d-4524-a855-acc51ce795f3","jsonPOD":` + string(data) + `}`
+	if _, err := UnmarshalPodStrict([]byte(wrapped)); err == nil || !strings.Contains(err.Error(), `unknown field "id"`) {
+		t.Fatalf("expected wrapped POD to fail with unknown field, got %v", err)
+	}
+	extra := `{"extra":1,` + string(data[1:])
+	if _, err := UnmarshalPodStrict([]byte(extra)); err == nil || !strings.Contains(err.Error(), `unknown field "extra"`) {
+		t.Fatalf("expected extra field to fail, got %v", err)
+	}
+	if _, err := UnmarshalPodStrict(append(data, data...)); err == nil {
+		t.Fatalf("expected trailing data to fail")
+	}
+	if _, err := UnmarshalPodStrict([]byte(`{"entries":{}}`)); err == nil {
+		t.Fatalf("expected missing signature to fail")
+	}
+}
+
+func TestCheckFormatSignerPublicKey(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"A": NewPodStringValue("a"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	if err := pod.CheckFormat(); err != nil {
+		t.Fatalf("expected valid POD to pass: %v", err)
+	}
+
+	for _, publicKey := range []string{"", "not a key", pod.SignerPublicKey[:40], pod.Signature} {
+		bad := *pod
+		bad.SignerPublicKey = publicKey
+		if err := bad.CheckFormat(); err == nil || !strings.Contains(err.Error(), "signer public key") {
+			t.Fatalf("expected malformed public key %q to fail, got %v", publicKey, err)
+		}
+		data, err := json.Marshal(bad)
+		if err != nil {
+			t.Fatalf("failed to marshal POD: %v", err)
+		}
+		var parsed Pod
+		if err := json.Unmarshal(data, &parsed); err == nil {
+			t.Fatalf("expected malformed public key %q to fail to parse", publicKey)
+		}
+	}
+
+	bad := *pod
+	bad.Signature = pod.SignerPublicKey
+	if err := bad.CheckFormat(); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Fatalf("expected malformed signature to fail, got %v", err)
+	}
+}
+
+func TestSignBundle(t *testing.T) {
+	signer, err := NewSigner("0001020304050607080900010203040506070809000102030405060708090001")
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+
+	entries := PodEntries{
+		"A": PodValue{ValueType: PodIntValue, BigVal: big.NewInt(9007199254740992)},
+	}
+	bundle, err := signer.SignBundle(entries)
+	if err != nil {
+		t.Fatalf("SignBundle failed: %v", err)
+	}
+	contentID, err := ComputeContentID(entries)
+	if err != nil {
+		t.Fatalf("ComputeContentID failed: %v", err)
+	}
+	if bundle.ContentID != formatContentID(contentID) || len(bundle.ContentID) != 66 {
+		t.Fatalf("unexpected content ID: %s", bundle.ContentID)
+	}
+	if bundle.SignerPublicKey != "xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4" {
+		t.Fatalf("unexpected signer: %s", bundle.SignerPublicKey)
+	}
+	ok, err := bundle.Pod.Verify()
+	if err != nil || !ok {
+		t.Fatalf("bundled POD failed to verify: %v", err)
+	}
+
+	serialized, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("Failed to marshal bundle to JSON: %v", err)
+	}
+	var deserialized AttestationBundle
+	if err := json.Unmarshal(serialized, &deserialized); err != nil {
+		t.Fatalf("Failed to unmarshal bundle from JSON: %v", err)
+	}
+	if !deserialized.SignedAt.Equal(bundle.SignedAt) || deserialized.ContentID != bundle.ContentID {
+		t.Fatalf("bundle changed in round trip: %s", string(serialized))
+	}
+
+	// Metadata which doesn't match the POD should be rejected.
+	tampered := *bundle
+	tampered.ContentID = "0x01"
+	serialized, err = json.Marshal(tampered)
+	if err != nil {
+		t.Fatalf("Failed to marshal bundle to JSON: %v", err)
+	}
+	if err := json.Unmarshal(serialized, &deserialized); err == nil {
+		t.Fatalf("expected mismatched content ID to be rejected")
+	}
+
+	// The signing time and library version aren't signed, so changing them
+	// leaves the bundle valid.
+	tampered = *bundle
+	tampered.SignedAt = time.Unix(0, 0).UTC()
+	tampered.LibraryVersion = "0.0.0"
+	serialized, err = json.Marshal(tampered)
+	if err != nil {
+		t.Fatalf("Failed to marshal bundle to JSON: %v", err)
+	}
+	if err := json.Unmarshal(serialized, &deserialized); err != nil {
+		t.Fatalf("Failed to unmarshal bundle from JSON: %v", err)
+	}
+	ok, err = deserialized.Pod.Verify()
+	if err != nil || !ok {
+		t.Fatalf("bundled POD failed to verify: %v", err)
+	}
+}
+
+func TestSignedMessage(t *testing.T) {
+	privKey := babyjub.PrivateKey{
+		0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 0, 1, 2, 3, 4, 5, 6, 7, 8,
+		9, 0, 1,
+	}
+	pod, err := signPod(privKey, PodEntries{"A": NewPodStringValue("foobar")})
+	if err != nil {
+		t.Fatalf("signPod failed: %v", err)
+	}
+	message, err := pod.SignedMessage()
+	if err != nil {
+		t.Fatalf("SignedMessage failed: %v", err)
+	}
+	if len(message) != 32 {
+		t.Fatalf("expected 32-byte message, got %d", len(message))
+	}
+
+	// The message must be the little-endian content ID, which an independent
+	// verifier can decode and check against the signature.
+	contentID, err := ComputeContentID(pod.Entries)
+	if err != nil {
+		t.Fatalf("ComputeContentID failed: %v", err)
+	}
+	bigEndian := make([]byte, 32)
+	for i := range message {
+		bigEndian[31-i] = message[i]
+	}
+	if new(big.Int).SetBytes(bigEndian).Cmp(contentID) != 0 {
+		t.Fatalf("signed message does not encode the content ID")
+	}
+	sigBytes, err := DecodeBytes(pod.Signature, 64)
+	if err != nil {
+		t.Fatalf("Signature decode failed: %v", err)
+	}
+	sigComp := babyjub.SignatureComp(sigBytes)
+	sig, err := sigComp.Decompress()
+	if err != nil {
+		t.Fatalf("Signature decompress failed: %v", err)
+	}
+	if err := privKey.Public().VerifyPoseidon(new(big.Int).SetBytes(bigEndian), sig); err != nil {
+		t.Fatalf("signature does not verify against signed message: %v", err)
+	}
+}
+
+func TestVerifyBlobMembership(t *testing.T) {
+	blobs := [][]byte{[]byte("blob0"), []byte("blob1"), []byte("blob2"), []byte("blob3")}
+	leaves := make([]*big.Int, len(blobs))
+	for i, blob := range blobs {
+		leaves[i] = hashBytes(blob)
+	}
+	left, err := poseidon.Hash([]*big.Int{leaves[0], leaves[1]})
+	if err != nil {
+		t.Fatalf("hash failed: %v", err)
+	}
+	right, err := poseidon.Hash([]*big.Int{leaves[2], leaves[3]})
+	if err != nil {
+		t.Fatalf("hash failed: %v", err)
+	}
+	root, err := poseidon.Hash([]*big.Int{left, right})
+	if err != nil {
+		t.Fatalf("hash failed: %v", err)
+	}
+
+	rootValue, err := NewPodCryptographicValue(root)
+	if err != nil {
+		t.Fatalf("failed to create value: %v", err)
+	}
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"blobRoot": rootValue,
+		"label":    NewPodStringValue("archive"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+
+	proof := [][]byte{leaves[3].Bytes(), left.Bytes()}
+	ok, err := VerifyBlobMembership(pod, "blobRoot", blobs[2], proof, 2)
+	if err != nil || !ok {
+		t.Fatalf("expected blob membership to verify: %v", err)
+	}
+	ok, err = VerifyBlobMembership(pod, "blobRoot", blobs[1], proof, 2)
+	if err != nil || ok {
+		t.Fatalf("expected wrong blob not to verify: %v", err)
+	}
+	ok, err = VerifyBlobMembership(pod, "blobRoot", blobs[2], proof, 3)
+	if err != nil || ok {
+		t.Fatalf("expected wrong index not to verify: %v", err)
+	}
+	if _, err = VerifyBlobMembership(pod, "blobRoot", blobs[2], proof, 4); err == nil {
+		t.Fatalf("expected out of range index to fail")
+	}
+	if _, err = VerifyBlobMembership(pod, "label", blobs[2], proof, 2); err == nil {
+		t.Fatalf("expected non-cryptographic root entry to fail")
+	}
+	if _, err = VerifyBlobMembership(pod, "missing", blobs[2], proof, 2); err == nil {
+		t.Fatalf("expected missing root entry to fail")
+	}
+}
+
+func TestVerifyKeyBinding(t *testing.T) {
+	privKeyHex := "0001020304050607080900010203040506070809000102030405060708090001"
+	selfKey, err := NewPodEdDSAPubkeyValue("c433f7a696b7aa3a5224efb3993baf0ccd9e92eecee0c29a3f6c8208a9e81d9e")
+	if err != nil {
+		t.Fatalf("failed to create value: %v", err)
+	}
+	otherKey, err := NewPodEdDSAPubkeyValue("kfEJWsAZtQYQtctW5ds4iRd/7otkIvyj2sBO4ZMkMak")
+	if err != nil {
+		t.Fatalf("failed to create value: %v", err)
+	}
+	pod, err := CreatePod(privKeyHex, PodEntries{
+		"issuer": selfKey,
+		"other":  otherKey,
+		"name":   NewPodStringValue("self"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+
+	// The hex-encoded entry matches the base64-encoded signer.
+	ok, err := pod.VerifyKeyBinding("issuer")
+	if err != nil || !ok {
+		t.Fatalf("expected key binding to verify: %v", err)
+	}
+	ok, err = pod.VerifyKeyBinding("other")
+	if err != nil || ok {
+		t.Fatalf("expected key binding to a different key to fail: %v", err)
+	}
+	if _, err = pod.VerifyKeyBinding("missing"); !errors.Is(err, ErrMissingEntry) {
+		t.Fatalf("expected missing entry error, got %v", err)
+	}
+	if _, err = pod.VerifyKeyBinding("name"); !errors.Is(err, ErrWrongEntryType) {
+		t.Fatalf("expected wrong type error, got %v", err)
+	}
+}
+
+func TestVerifyChallenge(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"nonce": NewPodStringValue("8209fd10-667d-4524-a855-acc51ce795f3"),
+		"count": PodValue{ValueType: PodIntValue, BigVal: big.NewInt(1)},
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+
+	ok, err := pod.VerifyChallenge("nonce", "8209fd10-667d-4524-a855-acc51ce795f3")
+	if err != nil || !ok {
+		t.Fatalf("expected challenge to verify: %v", err)
+	}
+	ok, err = pod.VerifyChallenge("nonce", "some other nonce")
+	if err != nil || ok {
+		t.Fatalf("expected wrong nonce to fail: %v", err)
+	}
+	if _, err = pod.VerifyChallenge("missing", "8209fd10-667d-4524-a855-acc51ce795f3"); !errors.Is(err, ErrMissingEntry) {
+		t.Fatalf("expected missing entry error, got %v", err)
+	}
+	if _, err = pod.VerifyChallenge("count", "1"); !errors.Is(err, ErrWrongEntryType) {
+		t.Fatalf("expected wrong type error, got %v", err)
+	}
+}
+
+func TestVersionedKeySet(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+
+	// The same key in hex should match the POD's base64 signer.
+	keySet, err := NewVersionedKeySet("2025-01", []string{
+		"c433f7a696b7aa3a5224efb3993baf0ccd9e92eecee0c29a3f6c8208a9e81d9e",
+	})
+	if err != nil {
+		t.Fatalf("NewVersionedKeySet failed: %v", err)
+	}
+	version, ok, err := keySet.Verify(pod)
+	if err != nil || !ok || version != "2025-01" {
+		t.Fatalf("expected POD to verify with key set version: %q %v %v", version, ok, err)
+	}
+
+	otherKeySet, err := NewVersionedKeySet("2025-02", []string{"kfEJWsAZtQYQtctW5ds4iRd/7otkIvyj2sBO4ZMkMak"})
+	if err != nil {
+		t.Fatalf("NewVersionedKeySet failed: %v", err)
+	}
+	version, ok, err = otherKeySet.Verify(pod)
+	if !errors.Is(err, ErrUntrustedSigner) || ok || version != "" {
+		t.Fatalf("expected untrusted signer error: %q %v %v", version, ok, err)
+	}
+
+	pod.Entries["message"] = NewPodStringValue("tampered")
+	version, ok, err = keySet.Verify(pod)
+	if err != nil || ok || version != "" {
+		t.Fatalf("expected tampered POD to fail: %q %v %v", version, ok, err)
+	}
+
+	if _, err := NewVersionedKeySet("bad", []string{"not a key"}); err == nil {
+		t.Fatalf("expected malformed key to be rejected")
+	}
+}
+
+func TestSignerLimits(t *testing.T) {
+	signer, err := NewSigner("0001020304050607080900010203040506070809000102030405060708090001")
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	entries := PodEntries{
+		"abc": NewPodStringValue("defgh"),
+		"xyz": PodValue{ValueType: PodBytesValue, BytesVal: []byte{1, 2}},
+	}
+
+	// No limits by default
+	if _, err := signer.Sign(entries); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	signer.MaxEntries = 1
+	if _, err := signer.Sign(entries); err == nil {
+		t.Fatalf("expected too many entries to fail")
+	}
+	if _, err := signer.SignBundle(entries); err == nil {
+		t.Fatalf("expected too many entries to fail")
+	}
+	signer.MaxEntries = 2
+
+	// Names and values add up to 3+5+3+2 bytes
+	signer.MaxHashedBytes = 12
+	if _, err := signer.Sign(entries); err == nil {
+		t.Fatalf("expected too many hashed bytes to fail")
+	}
+	signer.MaxHashedBytes = 13
+	if _, err := signer.Sign(entries); err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	canonical := *pod
+
+	changed, err := pod.Normalize()
+	if err != nil || changed {
+		t.Fatalf("canonical POD should not change: %v %v", changed, err)
+	}
+
+	// Padded Base64
+	pod.Signature = canonical.Signature + "=="
+	pod.SignerPublicKey = canonical.SignerPublicKey + "="
+	changed, err = pod.Normalize()
+	if err != nil || !changed {
+		t.Fatalf("padded POD should change: %v %v", changed, err)
+	}
+	if pod.Signature != canonical.Signature || pod.SignerPublicKey != canonical.SignerPublicKey {
+		t.Fatalf("padded POD not normalized: %v", pod)
+	}
+
+	// Hex
+	sigBytes, err := DecodeBytes(canonical.Signature, 64)
+	if err != nil {
+		t.Fatalf("Signature decode failed: %v", err)
+	}
+	pod.Signature = hex.EncodeToString(sigBytes)
+	changed, err = pod.Normalize()
+	if err != nil || !changed {
+		t.Fatalf("hex POD should change: %v %v", changed, err)
+	}
+	if pod.Signature != canonical.Signature || pod.SignerPublicKey != canonical.SignerPublicKey {
+		t.Fatalf("hex POD not normalized: %v", pod)
+	}
+
+	pod.Signature = "not a signature"
+	if _, err = pod.Normalize(); err == nil {
+		t.Fatalf("expected malformed signature to fail")
+	}
+}
+
+func TestSignerValue(t *testing.T) {
+	pod := Pod{
+		Entries:         PodEntries{},
+		Signature:       "",
+		SignerPublicKey: "c433f7a696b7aa3a5224efb3993baf0ccd9e92eecee0c29a3f6c8208a9e81d9e",
+	}
+	value, err := pod.SignerValue()
+	if err != nil {
+		t.Fatalf("SignerValue failed: %v", err)
+	}
+	if value.ValueType != PodEdDSAPubkeyValue || value.StringVal != "xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4" {
+		t.Fatalf("unexpected signer value: %v", value)
+	}
+
+	pod.SignerPublicKey = "xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ"
+	if _, err := pod.SignerValue(); err == nil {
+		t.Fatalf("expected malformed signer to fail")
+	}
+}
+
+func TestVerifyNotExpired(t *testing.T) {
+	expiresAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"expires_at": PodValue{ValueType: PodDateValue, TimeVal: expiresAt},
+		"name":       NewPodStringValue("Alice"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+
+	ok, err := pod.VerifyNotExpired("expires_at", expiresAt.Add(-time.Millisecond))
+	if err != nil || !ok {
+		t.Fatalf("expected unexpired POD to verify: %v", err)
+	}
+	ok, err = pod.VerifyNotExpired("expires_at", expiresAt)
+	if !errors.Is(err, ErrExpired) || ok {
+		t.Fatalf("expected expired error, got %v", err)
+	}
+	_, err = pod.VerifyNotExpired("valid_until", expiresAt)
+	if !errors.Is(err, ErrMissingExpiry) || !errors.Is(err, ErrMissingEntry) {
+		t.Fatalf("expected missing expiry error, got %v", err)
+	}
+	if _, err = pod.VerifyNotExpired("name", expiresAt); !errors.Is(err, ErrWrongEntryType) {
+		t.Fatalf("expected wrong type error, got %v", err)
+	}
+}
+
+func TestVerifyWithTrustChain(t *testing.T) {
+	rootKey := "0001020304050607080900010203040506070809000102030405060708090001"
+	issuerKey := "0101010101010101010101010101010101010101010101010101010101010101"
+	issuerPubKey, err := EdDSAPublicKeyFromPrivate(issuerKey)
+	if err != nil {
+		t.Fatalf("failed to derive public key: %v", err)
+	}
+	issuerValue, err := NewPodEdDSAPubkeyValue(issuerPubKey)
+	if err != nil {
+		t.Fatalf("failed to create value: %v", err)
+	}
+
+	authorizer, err := CreatePod(rootKey, PodEntries{"issuer0": issuerValue})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	leaf, err := CreatePod(issuerKey, PodEntries{"name": NewPodStringValue("Alice")})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	rootPubKey := authorizer.SignerPublicKey
+
+	ok, err := VerifyWithTrustChain(leaf, authorizer, rootPubKey)
+	if err != nil || !ok {
+		t.Fatalf("expected trust chain to verify: %v", err)
+	}
+
+	// The root doesn't authorize itself as an issuer.
+	if _, err = VerifyWithTrustChain(authorizer, authorizer, rootPubKey); !errors.Is(err, ErrUntrustedSigner) {
+		t.Fatalf("expected unauthorized leaf signer, got %v", err)
+	}
+	// The authorizer must be signed by the root.
+	if _, err = VerifyWithTrustChain(leaf, leaf, rootPubKey); !errors.Is(err, ErrUntrustedSigner) {
+		t.Fatalf("expected untrusted authorizer, got %v", err)
+	}
+
+	leaf.Entries["name"] = NewPodStringValue("Mallory")
+	ok, err = VerifyWithTrustChain(leaf, authorizer, rootPubKey)
+	if err != nil || ok {
+		t.Fatalf("expected tampered leaf to fail: %v", err)
+	}
+}
+
+func TestVerifyBytesCommitment(t *testing.T) {
+	document := []byte("The quick brown fox jumps over the lazy dog")
+	digest := sha256.Sum256(document)
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"document": PodValue{ValueType: PodBytesValue, BytesVal: digest[:]},
+		"title":    NewPodStringValue("fox"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+
+	ok, err := pod.VerifyBytesCommitment("document", document)
+	if err != nil || !ok {
+		t.Fatalf("expected commitment to verify: %v", err)
+	}
+	ok, err = pod.VerifyBytesCommitment("document", []byte("The quick brown fox jumps over the lazy cat"))
+	if err != nil || ok {
+		t.Fatalf("expected other document to fail: %v", err)
+	}
+	if _, err = pod.VerifyBytesCommitment("missing", document); !errors.Is(err, ErrMissingEntry) {
+		t.Fatalf("expected missing entry error, got %v", err)
+	}
+	if _, err = pod.VerifyBytesCommitment("title", document); !errors.Is(err, ErrWrongEntryType) {
+		t.Fatalf("expected wrong type error, got %v", err)
+	}
+}
+
+func TestMapKey(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	key, err := pod.MapKey()
+	if err != nil {
+		t.Fatalf("MapKey failed: %v", err)
+	}
+	parts := strings.Split(key, ":")
+	if len(parts) != 3 || len(parts[0]) != 64 || parts[1] != "c433f7a696b7aa3a5224efb3993baf0ccd9e92eecee0c29a3f6c8208a9e81d9e" || len(parts[2]) != 128 {
+		t.Fatalf("unexpected map key: %s", key)
+	}
+
+	// The same POD with differently-encoded fields has the same key.
+	sigBytes, err := DecodeBytes(pod.Signature, 64)
+	if err != nil {
+		t.Fatalf("Signature decode failed: %v", err)
+	}
+	reencoded := Pod{
+		Entries:         PodEntries{"message": NewPodStringValue("hello")},
+		Signature:       hex.EncodeToString(sigBytes),
+		SignerPublicKey: pod.SignerPublicKey + "=",
+	}
+	reencodedKey, err := reencoded.MapKey()
+	if err != nil {
+		t.Fatalf("MapKey failed: %v", err)
+	}
+	if reencodedKey != key {
+		t.Fatalf("map keys differ: %s %s", key, reencodedKey)
+	}
+
+	reencoded.Entries["message"] = NewPodStringValue("goodbye")
+	otherKey, err := reencoded.MapKey()
+	if err != nil {
+		t.Fatalf("MapKey failed: %v", err)
+	}
+	if otherKey == key {
+		t.Fatalf("different entries should have different map keys")
+	}
+}
+
+func TestDisplayJSON(t *testing.T) {
+	pod := Pod{
+		Entries: PodEntries{
+			"A": PodValue{ValueType: PodIntValue, BigVal: big.NewInt(9007199254740992)},
+			"C": PodValue{ValueType: PodBooleanValue, BoolVal: false},
+			"D": PodValue{ValueType: PodDateValue, TimeVal: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			"J": PodValue{ValueType: PodBytesValue, BytesVal: []byte{0x01, 0x02, 0x03}},
+			"K": PodValue{ValueType: PodCryptographicValue, BigVal: big.NewInt(1234567890)},
+			"N": PodValue{ValueType: PodNullValue},
+			"S": PodValue{ValueType: PodStringValue, StringVal: "foobar"},
+			"P": PodValue{ValueType: PodEdDSAPubkeyValue, StringVal: "xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4"},
+		},
+	}
+	display, err := pod.DisplayJSON()
+	if err != nil {
+		t.Fatalf("DisplayJSON failed: %v", err)
+	}
+	expected := `[{"name":"A","type":"int","value":"9007199254740992"},{"name":"C","type":"boolean","value":false},{"name":"D","type":"date","value":"2025-01-01T00:00:00.000Z"},{"name":"J","type":"bytes","value":"AQID"},{"name":"K","type":"cryptographic","value":"1234567890"},{"name":"N","type":"null","value":null},{"name":"P","type":"eddsa_pubkey","value":"xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4"},{"name":"S","type":"string","value":"foobar"}]`
+	if string(display) != expected {
+		t.Fatalf("unexpected display JSON: %s", display)
+	}
+
+	pod.Entries["bad name"] = NewPodNullValue()
+	if _, err := pod.DisplayJSON(); err == nil {
+		t.Fatalf("expected invalid entries to fail")
+	}
+}
+
+func TestVerifyStringOneOf(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"role":  NewPodStringValue("editor"),
+		"count": PodValue{ValueType: PodIntValue, BigVal: big.NewInt(1)},
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+
+	ok, err := pod.VerifyStringOneOf("role", "admin", "editor")
+	if err != nil || !ok {
+		t.Fatalf("expected allowed role to verify: %v", err)
+	}
+	if _, err = pod.VerifyStringOneOf("role", "admin", "viewer"); !errors.Is(err, ErrValueNotAllowed) {
+		t.Fatalf("expected value not allowed error, got %v", err)
+	}
+	if _, err = pod.VerifyStringOneOf("role"); !errors.Is(err, ErrValueNotAllowed) {
+		t.Fatalf("expected empty allowed set to fail, got %v", err)
+	}
+	if _, err = pod.VerifyStringOneOf("missing", "editor"); !errors.Is(err, ErrMissingEntry) {
+		t.Fatalf("expected missing entry error, got %v", err)
+	}
+	if _, err = pod.VerifyStringOneOf("count", "1"); !errors.Is(err, ErrWrongEntryType) {
+		t.Fatalf("expected wrong type error, got %v", err)
+	}
+}
+
+func TestCreatePodFromJSON(t *testing.T) {
+	const privateKey = "0001020304050607080900010203040506070809000102030405060708090001"
+	pod, err := CreatePodFromJSON(privateKey, []byte(`{"message":"hello","count":{"int":3}}`))
+	if err != nil {
+		t.Fatalf("CreatePodFromJSON failed: %v", err)
+	}
+	expected, err := CreatePod(privateKey, PodEntries{
+		"message": NewPodStringValue("hello"),
+		"count":   PodValue{ValueType: PodIntValue, BigVal: big.NewInt(3)},
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	if pod.Signature != expected.Signature {
+		t.Fatalf("signature %s differs from %s", pod.Signature, expected.Signature)
+	}
+
+	for _, data := range []string{"", "[]", `{"bad name":1}`, `{"a":1,"a":2}`} {
+		if _, err := CreatePodFromJSON(privateKey, []byte(data)); err == nil || !strings.HasPrefix(err.Error(), "failed to parse entries") {
+			t.Fatalf("expected %q to fail to parse, got %v", data, err)
+		}
+	}
+	if _, err := CreatePodFromJSON("bad", []byte(`{}`)); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Fatalf("expected invalid private key error, got %v", err)
+	}
+}
+
+func TestAssemblePod(t *testing.T) {
+	entries := PodEntries{
+		"message": NewPodStringValue("hello"),
+	}
+	privateKey, err := parsePrivateKey("0001020304050607080900010203040506070809000102030405060708090001")
+	if err != nil {
+		t.Fatalf("parsePrivateKey failed: %v", err)
+	}
+	contentID, err := ComputeContentID(entries)
+	if err != nil {
+		t.Fatalf("ComputeContentID failed: %v", err)
+	}
+	sig, err := privateKey.SignPoseidon(contentID)
+	if err != nil {
+		t.Fatalf("SignPoseidon failed: %v", err)
+	}
+	sigBytes := sig.Compress()
+	pubKeyBytes := privateKey.Public().Compress()
+
+	pod, err := AssemblePod(entries, sigBytes[:], pubKeyBytes[:])
+	if err != nil {
+		t.Fatalf("AssemblePod failed: %v", err)
+	}
+	expected, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", entries)
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	if pod.Signature != expected.Signature || pod.SignerPublicKey != expected.SignerPublicKey {
+		t.Fatalf("assembled POD differs from signed POD: %v", pod)
+	}
+
+	if _, err := AssemblePod(entries, sigBytes[:63], pubKeyBytes[:]); !errors.Is(err, ErrMalformedSignature) {
+		t.Fatalf("expected short signature to fail, got %v", err)
+	}
+	if _, err := AssemblePod(entries, sigBytes[:], pubKeyBytes[:31]); !errors.Is(err, ErrMalformedPublicKey) {
+		t.Fatalf("expected short public key to fail, got %v", err)
+	}
+	otherEntries := PodEntries{"message": NewPodStringValue("tampered")}
+	if _, err := AssemblePod(otherEntries, sigBytes[:], pubKeyBytes[:]); !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("expected signature over other entries to fail, got %v", err)
+	}
+}
+
+func TestVerifyJSON(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	data, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatalf("failed to marshal POD: %v", err)
+	}
+	ok, err := VerifyJSON(data)
+	if err != nil || !ok {
+		t.Fatalf("expected POD to verify: %v", err)
+	}
+
+	tampered := bytes.Replace(data, []byte("hello"), []byte("jello"), 1)
+	ok, err = VerifyJSON(tampered)
+	if err != nil || ok {
+		t.Fatalf("expected tampered POD to be false without error: %v", err)
+	}
+
+	for _, bad := range []string{"", "{}", `{"entries":{"a":1}}`, string(data[:len(data)-1])} {
+		if _, err := VerifyJSON([]byte(bad)); !errors.Is(err, ErrMalformedPod) {
+			t.Fatalf("expected %q to fail to parse, got %v", bad, err)
+		}
+	}
+}
+
+func TestVerifyErrors(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+
+	// A signature for other entries is a mismatch, not an error.
+	tampered := *pod
+	tampered.Entries = PodEntries{"message": NewPodStringValue("tampered")}
+	ok, err := tampered.Verify()
+	if err != nil || ok {
+		t.Fatalf("expected mismatched signature to be false without error: %v", err)
+	}
+
+	for _, signature := range []string{"", "not a signature", pod.Signature[:80], hex.EncodeToString(bytes.Repeat([]byte{0xff}, 64))} {
+		bad := *pod
+		bad.Signature = signature
+		if _, err := bad.Verify(); !errors.Is(err, ErrMalformedSignature) {
+			t.Fatalf("expected malformed signature %q to fail, got %v", signature, err)
+		}
+	}
+	for _, publicKey := range []string{"", "not a key", hex.EncodeToString(bytes.Repeat([]byte{0xff}, 32))} {
+		bad := *pod
+		bad.SignerPublicKey = publicKey
+		if _, err := bad.Verify(); !errors.Is(err, ErrMalformedPublicKey) {
+			t.Fatalf("expected malformed public key %q to fail, got %v", publicKey, err)
+		}
+	}
+
+	if _, err := CreatePod("not a key", pod.Entries); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Fatalf("expected invalid private key error, got %v", err)
+	}
+	if _, err := NewSigner("0001"); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Fatalf("expected invalid private key error, got %v", err)
+	}
+}
+
+func TestVerifyWithPublicKey(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	privateKey, err := parsePrivateKey("0001020304050607080900010203040506070809000102030405060708090001")
+	if err != nil {
+		t.Fatalf("parsePrivateKey failed: %v", err)
+	}
+	publicKey := privateKey.Public()
+
+	ok, err := VerifyWithPublicKey(pod.Entries, pod.Signature, publicKey)
+	if err != nil || !ok {
+		t.Fatalf("expected POD to verify: %v", err)
+	}
+	otherPrivateKey, err := parsePrivateKey("0101020304050607080900010203040506070809000102030405060708090001")
+	if err != nil {
+		t.Fatalf("parsePrivateKey failed: %v", err)
+	}
+	otherKey := otherPrivateKey.Public()
+	ok, err = VerifyWithPublicKey(pod.Entries, pod.Signature, otherKey)
+	if err != nil || ok {
+		t.Fatalf("expected POD to fail with other key: %v", err)
+	}
+	if _, err := VerifyWithPublicKey(pod.Entries, pod.Signature, nil); err == nil {
+		t.Fatalf("expected nil key to fail")
+	}
+	if _, err := VerifyWithPublicKey(pod.Entries, "bad signature", publicKey); err == nil {
+		t.Fatalf("expected bad signature to fail")
+	}
+}
+
+func BenchmarkVerify(b *testing.B) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		b.Fatalf("CreatePod failed: %v", err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if ok, err := pod.Verify(); err != nil || !ok {
+			b.Fatalf("expected POD to verify: %v", err)
+		}
+	}
+}
+
+func BenchmarkVerifyWithPublicKey(b *testing.B) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		b.Fatalf("CreatePod failed: %v", err)
+	}
+	privateKey, err := parsePrivateKey("0001020304050607080900010203040506070809000102030405060708090001")
+	if err != nil {
+		b.Fatalf("parsePrivateKey failed: %v", err)
+	}
+	publicKey := privateKey.Public()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if ok, err := VerifyWithPublicKey(pod.Entries, pod.Signature, publicKey); err != nil || !ok {
+			b.Fatalf("expected POD to verify: %v", err)
+		}
+	}
+}
+
+func TestPodJSONEqual(t *testing.T) {
+	const compact = `{"entries":{"count":42,"some_bytes":{"bytes":"AQID"}},"signature":"p2HfR2I76RySPV7WM+rhdBjV+VVipIyQe2WilgwZGJ817gFkossK6KqVR2C8JNvhUoGHxb4XvDrRRJQnoo87Ag","signerPublicKey":"kfEJWsAZtQYQtctW5ds4iRd/7otkIvyj2sBO4ZMkMak"}`
+	const reformatted = `{
+		"signerPublicKey": "91f1095ac019b50610b5cb56e5db3889177fee8b6422fca3dac04ee1932431a9",
+		"signature": "p2HfR2I76RySPV7WM+rhdBjV+VVipIyQe2WilgwZGJ817gFkossK6KqVR2C8JNvhUoGHxb4XvDrRRJQnoo87Ag==",
+		"entries": {"some_bytes": {"bytes": "AQID"}, "count": {"int": 42}}
+	}`
+	equal, err := PodJSONEqual([]byte(compact), []byte(reformatted))
+	if err != nil || !equal {
+		t.Fatalf("expected PODs to be equal: %v", err)
+	}
+
+	const otherEntries = `{"entries":{"count":43,"some_bytes":{"bytes":"AQID"}},"signature":"p2HfR2I76RySPV7WM+rhdBjV+VVipIyQe2WilgwZGJ817gFkossK6KqVR2C8JNvhUoGHxb4XvDrRRJQnoo87Ag","signerPublicKey":"kfEJWsAZtQYQtctW5ds4iRd/7otkIvyj2sBO4ZMkMak"}`
+	equal, err = PodJSONEqual([]byte(compact), []byte(otherEntries))
+	if err != nil || equal {
+		t.Fatalf("expected PODs with different entries to differ: %v", err)
+	}
+
+	if _, err := PodJSONEqual([]byte(compact), []byte("{not json")); err == nil {
+		t.Fatalf("expected malformed POD to fail")
+	}
+}
+
+func TestVerifyRejectsNonCanonicalSignature(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	signatureBytes, err := DecodeBytes(pod.Signature, 64)
+	if err != nil {
+		t.Fatalf("DecodeBytes failed: %v", err)
+	}
+	sigComp := babyjub.SignatureComp(signatureBytes)
+	signature, err := sigComp.Decompress()
+	if err != nil {
+		t.Fatalf("Decompress failed: %v", err)
+	}
+
+	// Adding the subgroup order to S produces a different signature which
+	// babyjub itself still accepts.
+	signature.S = new(big.Int).Add(signature.S, babyjub.SubOrder)
+	contentID, err := ComputeContentID(pod.Entries)
+	if err != nil {
+		t.Fatalf("ComputeContentID failed: %v", err)
+	}
+	privateKey, err := parsePrivateKey("0001020304050607080900010203040506070809000102030405060708090001")
+	if err != nil {
+		t.Fatalf("parsePrivateKey failed: %v", err)
+	}
+	if err := privateKey.Public().VerifyPoseidon(contentID, signature); err != nil {
+		t.Fatalf("expected babyjub to accept non-canonical signature: %v", err)
+	}
+
+	malleated := signature.Compress()
+	pod.Signature = noPadB64.EncodeToString(malleated[:])
+	ok, err := pod.Verify()
+	if ok || !errors.Is(err, ErrNonCanonicalSignature) {
+		t.Fatalf("expected non-canonical signature error, got %v %v", ok, err)
+	}
+}
+
+func TestLazyPod(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+
+	lazy := NewLazyPod(pod)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if value, err := lazy.Get("message"); err != nil || value.StringVal != "hello" {
+				t.Errorf("expected verified entry: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if entries, err := lazy.Entries(); err != nil || len(entries) != 1 {
+		t.Fatalf("expected verified entries: %v", err)
+	}
+	if _, err := lazy.Get("missing"); !errors.Is(err, ErrMissingEntry) {
+		t.Fatalf("expected missing entry error, got %v", err)
+	}
+
+	tampered := *pod
+	tampered.Entries = PodEntries{"message": NewPodStringValue("tampered")}
+	lazy = NewLazyPod(&tampered)
+	if _, err := lazy.Entries(); !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("expected signature mismatch error, got %v", err)
+	}
+	if _, err := lazy.Get("message"); !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("expected signature mismatch error, got %v", err)
+	}
+}
+
+func TestDedupePods(t *testing.T) {
+	first, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	second, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("goodbye"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	signatureBytes, err := DecodeBytes(first.Signature, 64)
+	if err != nil {
+		t.Fatalf("DecodeBytes failed: %v", err)
+	}
+	hexFirst := &Pod{
+		Entries:         first.Entries,
+		Signature:       hex.EncodeToString(signatureBytes),
+		SignerPublicKey: first.SignerPublicKey + "=",
+	}
+
+	unique, err := DedupePods([]*Pod{hexFirst, second, first, second})
+	if err != nil {
+		t.Fatalf("DedupePods failed: %v", err)
+	}
+	if len(unique) != 2 {
+		t.Fatalf("expected 2 unique PODs, got %d", len(unique))
+	}
+	if unique[0].Signature != first.Signature || unique[0].SignerPublicKey != first.SignerPublicKey {
+		t.Fatalf("expected first POD in canonical form, got %v", unique[0])
+	}
+	if unique[1].Entries["message"].StringVal != "goodbye" {
+		t.Fatalf("expected second POD, got %v", unique[1])
+	}
+	if hexFirst.Signature == first.Signature {
+		t.Fatalf("input POD should not be modified")
+	}
+
+	if _, err := DedupePods([]*Pod{first, {Entries: first.Entries, Signature: "bad"}}); err == nil {
+		t.Fatalf("expected malformed POD to fail")
+	}
+}
+
+func TestSafeRangeBoundaryRoundTrip(t *testing.T) {
+	twoTo53 := new(big.Int).Lsh(big.NewInt(1), 53)
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"intAtBoundary":   PodValue{ValueType: PodIntValue, BigVal: new(big.Int).Set(twoTo53)},
+		"intBelow":        PodValue{ValueType: PodIntValue, BigVal: new(big.Int).Sub(twoTo53, big.NewInt(1))},
+		"negativeSafe":    PodValue{ValueType: PodIntValue, BigVal: big.NewInt(-9007199254740991)},
+		"negativeUnsafe":  PodValue{ValueType: PodIntValue, BigVal: new(big.Int).Neg(twoTo53)},
+		"cryptoAbove":     PodValue{ValueType: PodCryptographicValue, BigVal: new(big.Int).Add(twoTo53, big.NewInt(1))},
+		"cryptoAtSafeMax": PodValue{ValueType: PodCryptographicValue, BigVal: new(big.Int).Sub(twoTo53, big.NewInt(1))},
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	jsonPod, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatalf("Failed to marshal pod to JSON: %v", err)
+	}
+	expectedEntries := `{"entries":{"cryptoAbove":{"cryptographic":"0x20000000000001"},"cryptoAtSafeMax":{"cryptographic":9007199254740991},"intAtBoundary":{"int":"0x20000000000000"},"intBelow":9007199254740991,"negativeSafe":-9007199254740991,"negativeUnsafe":{"int":"-9007199254740992"}},`
+	if !strings.HasPrefix(string(jsonPod), expectedEntries) {
+		t.Fatalf("unexpected serialization: %s", jsonPod)
+	}
+
+	var parsed Pod
+	if err := json.Unmarshal(jsonPod, &parsed); err != nil {
+		t.Fatalf("Failed to unmarshal pod: %v", err)
+	}
+	for name, value := range pod.Entries {
+		if !parsed.Entries[name].Equal(value) {
+			t.Fatalf("entry %s changed in round trip: %v became %v", name, value, parsed.Entries[name])
+		}
+	}
+	reserialized, err := json.Marshal(&parsed)
+	if err != nil {
+		t.Fatalf("Failed to marshal pod to JSON: %v", err)
+	}
+	if string(reserialized) != string(jsonPod) {
+		t.Fatalf("serialization is not stable:\n%s\n%s", jsonPod, reserialized)
+	}
+	ok, err := parsed.Verify()
+	if err != nil || !ok {
+		t.Fatalf("expected round-tripped POD to verify: %v", err)
+	}
+
+	if _, err := json.Marshal(PodValue{ValueType: PodCryptographicValue}); err == nil {
+		t.Fatalf("expected nil cryptographic value to fail to marshal")
+	}
+}
+
+func TestContentID(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	contentID, err := pod.ContentID()
+	if err != nil {
+		t.Fatalf("ContentID failed: %v", err)
+	}
+	expected, err := poseidon.Hash([]*big.Int{hashString("message"), hashString("hello")})
+	if err != nil {
+		t.Fatalf("hash failed: %v", err)
+	}
+	if contentID.Cmp(expected) != 0 {
+		t.Fatalf("unexpected content ID %v, expected %v", contentID, expected)
+	}
+	contentIDHex, err := pod.ContentIDHex()
+	if err != nil {
+		t.Fatalf("ContentIDHex failed: %v", err)
+	}
+	if len(contentIDHex) != 66 || !strings.HasPrefix(contentIDHex, "0x") {
+		t.Fatalf("unexpected content ID hex %s", contentIDHex)
+	}
+	if parsed, ok := new(big.Int).SetString(contentIDHex[2:], 16); !ok || parsed.Cmp(contentID) != 0 {
+		t.Fatalf("content ID hex %s doesn't match %v", contentIDHex, contentID)
+	}
+
+	pod.Entries["bad name"] = NewPodNullValue()
+	if _, err := pod.ContentID(); err == nil {
+		t.Fatalf("expected invalid entries to fail")
+	}
+}
+
+func TestVerifyContains(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"count":  PodValue{ValueType: PodIntValue, BigVal: big.NewInt(5)},
+		"name":   NewPodStringValue("Alice"),
+		"role":   NewPodStringValue("admin"),
+		"secret": PodValue{ValueType: PodCryptographicValue, BigVal: big.NewInt(7)},
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+
+	ok, err := pod.VerifyContains(PodEntries{
+		"count": PodValue{ValueType: PodIntValue, BigVal: big.NewInt(5)},
+		"role":  NewPodStringValue("admin"),
+	})
+	if err != nil || !ok {
+		t.Fatalf("expected template to match: %v", err)
+	}
+
+	_, err = pod.VerifyContains(PodEntries{
+		"count":  PodValue{ValueType: PodCryptographicValue, BigVal: big.NewInt(5)},
+		"email":  NewPodStringValue("alice@example.com"),
+		"member": NewPodBooleanValue(true),
+		"role":   NewPodStringValue("viewer"),
+		"secret": PodValue{ValueType: PodCryptographicValue, BigVal: big.NewInt(7)},
+	})
+	var mismatchErr *TemplateMismatchError
+	if !errors.As(err, &mismatchErr) || !errors.Is(err, ErrMissingEntry) || !errors.Is(err, ErrValueNotAllowed) {
+		t.Fatalf("expected template mismatch error, got %v", err)
+	}
+	if strings.Join(mismatchErr.Missing, ",") != "email,member" || strings.Join(mismatchErr.Mismatched, ",") != "count,role" {
+		t.Fatalf("unexpected mismatch %v", mismatchErr)
+	}
+
+	_, err = pod.VerifyContains(PodEntries{"role": NewPodStringValue("viewer")})
+	if errors.Is(err, ErrMissingEntry) || !errors.Is(err, ErrValueNotAllowed) {
+		t.Fatalf("expected only mismatched entries, got %v", err)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	if Version() == "" || SpecVersion() == "" {
+		t.Fatalf("expected versions to be set, got %q %q", Version(), SpecVersion())
+	}
+}
+
+func TestGenerateSigner(t *testing.T) {
+	signer, err := GenerateSigner()
+	if err != nil {
+		t.Fatalf("GenerateSigner failed: %v", err)
+	}
+	other, err := GenerateSigner()
+	if err != nil {
+		t.Fatalf("GenerateSigner failed: %v", err)
+	}
+	if signer.PrivateKeyHex() == other.PrivateKeyHex() {
+		t.Fatalf("expected generated keys to differ")
+	}
+
+	pod, err := signer.Sign(PodEntries{"message": NewPodStringValue("hello")})
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if ok, err := pod.Verify(); err != nil || !ok {
+		t.Fatalf("expected POD from generated key to verify: %v", err)
+	}
+	restored, err := NewSigner(signer.PrivateKeyHex())
+	if err != nil {
+		t.Fatalf("NewSigner failed: %v", err)
+	}
+	restoredPod, err := restored.Sign(PodEntries{"message": NewPodStringValue("hello")})
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if restoredPod.Signature != pod.Signature {
+		t.Fatalf("expected restored signer to produce the same signature")
+	}
+
+	defer func(original io.Reader) { RandSource = original }(RandSource)
+	RandSource = bytes.NewReader(make([]byte, 31))
+	if _, err := GenerateSigner(); err == nil {
+		t.Fatalf("expected short random source to fail")
+	}
+}
+
+func TestEdDSAPublicKeyFromPrivate(t *testing.T) {
+	for _, privateKey := range []string{
+		"0001020304050607080900010203040506070809000102030405060708090001",
+		"AAECAwQFBgcICQABAgMEBQYHCAkAAQIDBAUGBwgJAAE",
+		"AAECAwQFBgcICQABAgMEBQYHCAkAAQIDBAUGBwgJAAE=",
+	} {
+		publicKey, err := EdDSAPublicKeyFromPrivate(privateKey)
+		if err != nil {
+			t.Fatalf("EdDSAPublicKeyFromPrivate failed: %v", err)
+		}
+		if publicKey != "xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4" {
+			t.Fatalf("unexpected public key %s for %s", publicKey, privateKey)
+		}
+	}
+
+	_, err := EdDSAPublicKeyFromPrivate("000102030405060708090001003040506070809000102030405060708090001")
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to parse private key") {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+}
+
+func TestVerifyAll(t *testing.T) {
+	var pods []*Pod
+	for _, message := range []string{"a", "b", "c"} {
+		pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+			"message": NewPodStringValue(message),
+		})
+		if err != nil {
+			t.Fatalf("CreatePod failed: %v", err)
+		}
+		pods = append(pods, pod)
+	}
+	other, err := CreatePod("0101020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("d"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	pods = append(pods, other)
+	pods[1].Entries["message"] = NewPodStringValue("tampered")
+	pods = append(pods, &Pod{Entries: pods[0].Entries, Signature: "bad signature", SignerPublicKey: pods[0].SignerPublicKey})
+
+	results, err := VerifyAll(pods)
+	if err != nil {
+		t.Fatalf("VerifyAll failed: %v", err)
+	}
+	if len(results) != 5 || !results[0] || results[1] || !results[2] || !results[3] || results[4] {
+		t.Fatalf("unexpected results %v", results)
+	}
+
+	pods = append(pods, &Pod{Entries: pods[0].Entries, Signature: pods[0].Signature, SignerPublicKey: "bad key"})
+	if _, err := VerifyAll(pods); err == nil || !strings.HasPrefix(err.Error(), "POD 5:") {
+		t.Fatalf("expected malformed POD to fail with its index, got %v", err)
+	}
+}
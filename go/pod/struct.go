@@ -0,0 +1,133 @@
+package pod
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	bigIntType   = reflect.TypeOf((*big.Int)(nil))
+	timeType     = reflect.TypeOf(time.Time{})
+	bytesType    = reflect.TypeOf([]byte(nil))
+	podValueType = reflect.TypeOf(PodValue{})
+)
+
+// Verify a POD, then decode its entries into a new T, which must be a struct.
+// Fields are matched to entries by a `pod:"name"` tag, and fields without a tag
+// are ignored.  A tag of `pod:"name,optional"` allows the entry to be missing
+// or null, leaving the field at its zero value.
+//
+// Supported field types are string (string or eddsa_pubkey entries), bool,
+// sized and unsized ints and uints (int entries, which must fit), *big.Int
+// (int or cryptographic entries), time.Time, []byte, and PodValue (any entry).
+//
+// Returns an error wrapping ErrSignatureMismatch if the signature doesn't
+// match, or ErrMissingEntry or ErrWrongEntryType if the entries don't fit T.
+func VerifyInto[T any](p *Pod) (*T, error) {
+	ok, err := p.Verify()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrSignatureMismatch
+	}
+
+	result := new(T)
+	if err := p.Entries.decodeInto(reflect.ValueOf(result).Elem()); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Decode entries into the tagged fields of a struct.
+func (p PodEntries) decodeInto(out reflect.Value) error {
+	if out.Kind() != reflect.Struct {
+		return fmt.Errorf("can't decode POD entries into %s, which is not a struct", out.Type())
+	}
+	for i := 0; i < out.NumField(); i++ {
+		field := out.Type().Field(i)
+		tag, ok := field.Tag.Lookup("pod")
+		if !ok || tag == "-" || !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		optional := options == "optional"
+
+		value, ok := p[name]
+		if !ok || value.ValueType == PodNullValue {
+			if optional {
+				continue
+			}
+			if !ok {
+				return fmt.Errorf("%w: %q", ErrMissingEntry, name)
+			}
+		}
+		if err := decodeValue(value, out.Field(i)); err != nil {
+			return fmt.Errorf("%w: %q for field %s: %v", ErrWrongEntryType, name, field.Name, err)
+		}
+	}
+	return nil
+}
+
+// Decode a single value into a struct field.  The returned error describes the
+// mismatch, and is wrapped by the caller.
+func decodeValue(value PodValue, field reflect.Value) error {
+	switch field.Type() {
+	case podValueType:
+		field.Set(reflect.ValueOf(value))
+		return nil
+	case bigIntType:
+		if value.ValueType != PodIntValue && value.ValueType != PodCryptographicValue {
+			return fmt.Errorf("%s can't be decoded into *big.Int", value.ValueType)
+		}
+		field.Set(reflect.ValueOf(new(big.Int).Set(value.BigVal)))
+		return nil
+	case timeType:
+		if value.ValueType != PodDateValue {
+			return fmt.Errorf("%s can't be decoded into time.Time", value.ValueType)
+		}
+		field.Set(reflect.ValueOf(value.TimeVal))
+		return nil
+	case bytesType:
+		if value.ValueType != PodBytesValue {
+			return fmt.Errorf("%s can't be decoded into []byte", value.ValueType)
+		}
+		field.SetBytes(append([]byte{}, value.BytesVal...))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		if value.ValueType != PodStringValue && value.ValueType != PodEdDSAPubkeyValue {
+			return fmt.Errorf("%s can't be decoded into string", value.ValueType)
+		}
+		field.SetString(value.StringVal)
+	case reflect.Bool:
+		if value.ValueType != PodBooleanValue {
+			return fmt.Errorf("%s can't be decoded into bool", value.ValueType)
+		}
+		field.SetBool(value.BoolVal)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.ValueType != PodIntValue {
+			return fmt.Errorf("%s can't be decoded into %s", value.ValueType, field.Type())
+		}
+		if !value.BigVal.IsInt64() || field.OverflowInt(value.BigVal.Int64()) {
+			return fmt.Errorf("%s overflows %s", value.BigVal, field.Type())
+		}
+		field.SetInt(value.BigVal.Int64())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value.ValueType != PodIntValue {
+			return fmt.Errorf("%s can't be decoded into %s", value.ValueType, field.Type())
+		}
+		if !value.BigVal.IsUint64() || field.OverflowUint(value.BigVal.Uint64()) {
+			return fmt.Errorf("%s overflows %s", value.BigVal, field.Type())
+		}
+		field.SetUint(value.BigVal.Uint64())
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package pod
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+type testCredential struct {
+	Name     string    `pod:"name"`
+	Age      uint8     `pod:"age"`
+	Admin    bool      `pod:"admin"`
+	Issued   time.Time `pod:"issued"`
+	Secret   *big.Int  `pod:"secret"`
+	Photo    []byte    `pod:"photo"`
+	Nickname string    `pod:"nickname,optional"`
+	Raw      PodValue  `pod:"age"`
+	Ignored  string
+}
+
+func TestVerifyInto(t *testing.T) {
+	issued := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := PodEntries{
+		"name":   NewPodStringValue("Alice"),
+		"age":    PodValue{ValueType: PodIntValue, BigVal: big.NewInt(42)},
+		"admin":  NewPodBooleanValue(true),
+		"issued": PodValue{ValueType: PodDateValue, TimeVal: issued},
+		"secret": PodValue{ValueType: PodCryptographicValue, BigVal: big.NewInt(1234567890)},
+		"photo":  PodValue{ValueType: PodBytesValue, BytesVal: []byte{1, 2, 3}},
+	}
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", entries)
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+
+	credential, err := VerifyInto[testCredential](pod)
+	if err != nil {
+		t.Fatalf("VerifyInto failed: %v", err)
+	}
+	if credential.Name != "Alice" || credential.Age != 42 || !credential.Admin ||
+		!credential.Issued.Equal(issued) || credential.Secret.Int64() != 1234567890 ||
+		string(credential.Photo) != "\x01\x02\x03" || credential.Nickname != "" ||
+		credential.Raw.ValueType != PodIntValue {
+		t.Fatalf("unexpected credential: %+v", credential)
+	}
+
+	// Decoded values don't alias the POD's entries.
+	credential.Photo[0] = 9
+	credential.Secret.SetInt64(0)
+	if pod.Entries["photo"].BytesVal[0] != 1 || pod.Entries["secret"].BigVal.Int64() != 1234567890 {
+		t.Fatalf("decoding should copy mutable values")
+	}
+
+	type wrongType struct {
+		Name bool `pod:"name"`
+	}
+	if _, err := VerifyInto[wrongType](pod); !errors.Is(err, ErrWrongEntryType) {
+		t.Fatalf("expected wrong type error, got %v", err)
+	}
+	intPod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"negative": PodValue{ValueType: PodIntValue, BigVal: big.NewInt(-1)},
+		"large":    PodValue{ValueType: PodIntValue, BigVal: big.NewInt(300)},
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	type negativeUint struct {
+		Count uint `pod:"negative"`
+	}
+	if _, err := VerifyInto[negativeUint](intPod); !errors.Is(err, ErrWrongEntryType) {
+		t.Fatalf("expected negative uint to fail, got %v", err)
+	}
+	type overflowInt8 struct {
+		Count int8 `pod:"large"`
+	}
+	if _, err := VerifyInto[overflowInt8](intPod); !errors.Is(err, ErrWrongEntryType) {
+		t.Fatalf("expected int8 overflow to fail, got %v", err)
+	}
+	type missing struct {
+		Email string `pod:"email"`
+	}
+	if _, err := VerifyInto[missing](pod); !errors.Is(err, ErrMissingEntry) {
+		t.Fatalf("expected missing entry error, got %v", err)
+	}
+	if _, err := VerifyInto[string](pod); err == nil {
+		t.Fatalf("expected non-struct type to fail")
+	}
+
+	pod.Entries["name"] = NewPodStringValue("Mallory")
+	if _, err := VerifyInto[testCredential](pod); !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("expected signature mismatch error, got %v", err)
+	}
+}
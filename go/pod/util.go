@@ -6,19 +6,33 @@ import (
 	"fmt"
 )
 
-var noPadB64 = base64.NewEncoding("ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/").WithPadding(base64.NoPadding)
+// Standard Base64 without padding, which is the canonical encoding of bytes,
+// keys, and signatures in PODs, matching @pcd/pod.
+var noPadB64 = base64.RawStdEncoding
 
-// Decode a fixed number of bytes which may be encoded as hex, or Base64 with
-// or without padding. This will fail on any other encoding, or an unexpected
-// number of bytes.
+// Encode bytes in canonical unpadded standard Base64, as used for signatures,
+// public keys, and bytes values in PODs.  DecodeBytes and DecodeBase64Bytes
+// accept this encoding.
+func EncodeBytes(b []byte) string {
+	return noPadB64.EncodeToString(b)
+}
+
+// Decode a fixed number of bytes which may be encoded as hex with or without a
+// 0x prefix, or Base64 with or without padding in the standard or URL-safe
+// alphabet. This will fail on any other encoding, or an unexpected number of
+// bytes.
 func DecodeBytes(encodedBytes string, expectedBytes int) ([]byte, error) {
 	var decodedBytes []byte
 	var err error
 
-	if len(encodedBytes) == expectedBytes*2 {
-		decodedBytes, err = hex.DecodeString(encodedBytes)
+	hexBytes := encodedBytes
+	if len(hexBytes) >= 2 && hexBytes[0] == '0' && (hexBytes[1] == 'x' || hexBytes[1] == 'X') {
+		hexBytes = hexBytes[2:]
+	}
+	if len(hexBytes) == expectedBytes*2 {
+		decodedBytes, err = hex.DecodeString(hexBytes)
 		if err != nil {
-			return nil, fmt.Errorf("malformed private key: %w", err)
+			return nil, fmt.Errorf("malformed hex string: %w", err)
 		}
 	} else {
 		decodedBytes, err = DecodeBase64Bytes(encodedBytes)
@@ -34,14 +48,23 @@ func DecodeBytes(encodedBytes string, expectedBytes int) ([]byte, error) {
 	return decodedBytes, nil
 }
 
-// Decode a variable number of bytes in Base64 encoding, with or without padding.
+// Decode a variable number of bytes in Base64 encoding, with or without padding,
+// in the standard or URL-safe alphabet.
 func DecodeBase64Bytes(encodedBytes string) ([]byte, error) {
-	decodedBytes, err := noPadB64.DecodeString(encodedBytes)
-	if err != nil {
-		decodedBytes, err = base64.StdEncoding.DecodeString(encodedBytes)
-		if err != nil {
-			return nil, err
+	var firstErr error
+	for _, encoding := range []*base64.Encoding{
+		noPadB64,
+		base64.StdEncoding,
+		base64.RawURLEncoding,
+		base64.URLEncoding,
+	} {
+		decodedBytes, err := encoding.DecodeString(encodedBytes)
+		if err == nil {
+			return decodedBytes, nil
+		}
+		if firstErr == nil {
+			firstErr = err
 		}
 	}
-	return decodedBytes, nil
+	return nil, firstErr
 }
@@ -0,0 +1,97 @@
+package pod
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+)
+
+func TestDecodeBytes(t *testing.T) {
+	// Bytes chosen so that standard and URL-safe Base64 differ.
+	expected := bytes.Repeat([]byte{0xfb, 0xff, 0xbf}, 11)[:32]
+	for _, encoded := range []string{
+		hex.EncodeToString(expected),
+		"0x" + hex.EncodeToString(expected),
+		"0X" + hex.EncodeToString(expected),
+		base64.RawStdEncoding.EncodeToString(expected),
+		base64.StdEncoding.EncodeToString(expected),
+		base64.RawURLEncoding.EncodeToString(expected),
+		base64.URLEncoding.EncodeToString(expected),
+	} {
+		decoded, err := DecodeBytes(encoded, 32)
+		if err != nil {
+			t.Fatalf("DecodeBytes(%q) failed: %v", encoded, err)
+		}
+		if !bytes.Equal(decoded, expected) {
+			t.Fatalf("DecodeBytes(%q) returned %x", encoded, decoded)
+		}
+	}
+
+	for _, encoded := range []string{
+		"0x" + hex.EncodeToString(expected[:31]),
+		"0x" + hex.EncodeToString(append(expected, 0)),
+		"0x" + base64.RawStdEncoding.EncodeToString(expected),
+		base64.RawURLEncoding.EncodeToString(expected[:31]),
+		"+/-_" + base64.RawStdEncoding.EncodeToString(expected)[4:],
+	} {
+		if _, err := DecodeBytes(encoded, 32); err == nil {
+			t.Fatalf("expected DecodeBytes(%q) to fail", encoded)
+		}
+	}
+}
+
+func TestEncodeBytes(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	signatureBytes, err := DecodeBytes(pod.Signature, 64)
+	if err != nil {
+		t.Fatalf("DecodeBytes failed: %v", err)
+	}
+	if encoded := EncodeBytes(signatureBytes); encoded != pod.Signature {
+		t.Fatalf("expected %s, got %s", pod.Signature, encoded)
+	}
+
+	for _, b := range [][]byte{{}, {0xfb}, {0xfb, 0xff}, {0xfb, 0xff, 0xbf}} {
+		encoded := EncodeBytes(b)
+		if encoded != base64.RawStdEncoding.EncodeToString(b) {
+			t.Fatalf("expected unpadded standard Base64, got %q", encoded)
+		}
+		decoded, err := DecodeBase64Bytes(encoded)
+		if err != nil || !bytes.Equal(decoded, b) {
+			t.Fatalf("expected %q to decode to %x, got %x: %v", encoded, b, decoded, err)
+		}
+	}
+}
+
+func TestVerifyAlternateEncodings(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"message": NewPodStringValue("hello"),
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+	signatureBytes, err := DecodeBytes(pod.Signature, 64)
+	if err != nil {
+		t.Fatalf("DecodeBytes failed: %v", err)
+	}
+	publicKeyBytes, err := DecodeBytes(pod.SignerPublicKey, 32)
+	if err != nil {
+		t.Fatalf("DecodeBytes failed: %v", err)
+	}
+
+	alternate := *pod
+	alternate.Signature = "0x" + hex.EncodeToString(signatureBytes)
+	alternate.SignerPublicKey = base64.URLEncoding.EncodeToString(publicKeyBytes)
+	if err := alternate.CheckFormat(); err != nil {
+		t.Fatalf("expected alternate encodings to pass format check: %v", err)
+	}
+	ok, err := alternate.Verify()
+	if err != nil || !ok {
+		t.Fatalf("expected alternate encodings to verify: %v", err)
+	}
+}
@@ -1,14 +1,17 @@
 package pod
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"math/big"
 
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/iden3/go-iden3-crypto/v2/babyjub"
 	"github.com/iden3/go-iden3-crypto/v2/poseidon"
@@ -68,35 +71,46 @@ func newBigIntFromDecimalLiteral(decimalValue string) *big.Int {
 	return v
 }
 
+// Legal bounds of each value type, computed once.  These are shared, so they
+// must never be modified.  The exported accessors below return copies.
+var (
+	podCryptographicMin = big.NewInt(0)
+	podCryptographicMax = newBigIntFromDecimalLiteral("21888242871839275222246405745257275088548364400416034343698204186575808495616")
+	podIntMin           = big.NewInt(math.MinInt64)
+	podIntMax           = big.NewInt(math.MaxInt64)
+	podDateMin          = time.UnixMilli(-8_640_000_000_000_000)
+	podDateMax          = time.UnixMilli(8_640_000_000_000_000)
+)
+
 // Minimum legal POD cryptographic value is 0
 func PodCryptographicMin() *big.Int {
-	return big.NewInt(0)
+	return new(big.Int).Set(podCryptographicMin)
 }
 
 // Maximum legal POD cryptographic value is 1 less than the Baby Jubjub prime,
 // i.e. 21888242871839275222246405745257275088548364400416034343698204186575808495616
 func PodCryptographicMax() *big.Int {
-	return newBigIntFromDecimalLiteral("21888242871839275222246405745257275088548364400416034343698204186575808495616")
+	return new(big.Int).Set(podCryptographicMax)
 }
 
 // Minimum legal POD int value is -2^63
 func PodIntMin() *big.Int {
-	return big.NewInt(math.MinInt64)
+	return new(big.Int).Set(podIntMin)
 }
 
 // Maximum legal POD int value is 2^63-1
 func PodIntMax() *big.Int {
-	return big.NewInt(math.MaxInt64)
+	return new(big.Int).Set(podIntMax)
 }
 
 // Minimum legal POD int value is -8,640,000,000,000,000ms since epoch
 func PodDateMin() time.Time {
-	return time.UnixMilli(-8_640_000_000_000_000)
+	return podDateMin
 }
 
 // Maximum legal POD date value is 8,640,000,000,000,000ms since epoch
 func PodDateMax() time.Time {
-	return time.UnixMilli(8_640_000_000_000_000)
+	return podDateMax
 }
 
 // Constructor for null POD values
@@ -104,17 +118,43 @@ func NewPodNullValue() PodValue {
 	return PodValue{ValueType: PodNullValue}
 }
 
-// Constructor for string POD values
+// Constructor for string POD values.  The string is hashed exactly as given,
+// as in @pcd/pod, so strings which look the same but differ in Unicode
+// normalization (e.g. composed vs. decomposed accents) have different hashes.
+// Callers who want them to match should normalize before constructing.
 func NewPodStringValue(val string) PodValue {
 	return PodValue{ValueType: PodStringValue, StringVal: val}
 }
 
 // Constructor for bytes POD values.  Error if input is nil.
 func NewPodBytesValue(val []byte) (PodValue, error) {
-	value := PodValue{ValueType: PodBytesValue, BytesVal: val}
+	value := PodValue{ValueType: PodBytesValue}
+	if val != nil {
+		// Copy so that reuse of the caller's buffer can't change the value.
+		value.BytesVal = append([]byte{}, val...)
+	}
 	return value, value.Check()
 }
 
+// Constructor for bytes POD values read from r.  The value is identical to one
+// constructed from the same content in memory.  Since a bytes value is
+// serialized with its content, the content is always read in full; to hash a
+// large input without holding it in memory, use HashBytesReader instead.
+// If MaxBytesLen is set, reading stops as soon as the input exceeds it.
+func NewPodBytesValueFromReader(r io.Reader) (PodValue, error) {
+	if MaxBytesLen > 0 {
+		r = io.LimitReader(r, int64(MaxBytesLen)+1)
+	}
+	val, err := io.ReadAll(r)
+	if err != nil {
+		return PodValue{}, fmt.Errorf("failed to read bytes value: %w", err)
+	}
+	if MaxBytesLen > 0 && len(val) > MaxBytesLen {
+		return PodValue{}, fmt.Errorf("%s is more than the maximum of %d bytes", PodBytesValue, MaxBytesLen)
+	}
+	return NewPodBytesValue(val)
+}
+
 // Constructor for cryptographic POD values.  Error if input is nil or out of range.
 func NewPodCryptographicValue(val *big.Int) (PodValue, error) {
 	if val == nil {
@@ -144,13 +184,13 @@ func NewPodBooleanValue(val bool) PodValue {
 // Constructor for int POD values.  Error if input is not 32 bytes encoded in
 // Base64 (with or without padding).
 func NewPodEdDSAPubkeyValue(val string) (PodValue, error) {
-	value := PodValue{ValueType: PodEdDSAPubkeyValue, StringVal: val}
+	value := PodValue{ValueType: PodEdDSAPubkeyValue, StringVal: canonicalPublicKey(val)}
 	return value, value.Check()
 }
 
 // Constructor for int POD values.  Error if input is nil or out of range.
 func NewPodDateValue(val time.Time) (PodValue, error) {
-	if err := checkTimeBounds("", PodDateValue, val, PodDateMin(), PodDateMax()); err != nil {
+	if err := checkTimeBounds("", PodDateValue, val, podDateMin, podDateMax); err != nil {
 		return PodValue{}, err
 	}
 	// Explicitly truncate to millis, and force to UTC
@@ -159,6 +199,38 @@ func NewPodDateValue(val time.Time) (PodValue, error) {
 	return value, value.Check()
 }
 
+// Constructor for POD values of the type matching a Go value: string, bool,
+// int, int64, *big.Int, []byte, time.Time, or nil for null.  A *big.Int is an
+// int if it's in int range, otherwise a cryptographic.  Error for any other
+// Go type, or a value out of range for its POD type.
+func NewPodValue(v interface{}) (PodValue, error) {
+	switch val := v.(type) {
+	case nil:
+		return NewPodNullValue(), nil
+	case PodValue:
+		return val, val.Check()
+	case string:
+		return NewPodStringValue(val), nil
+	case bool:
+		return NewPodBooleanValue(val), nil
+	case int:
+		return NewPodIntValue(big.NewInt(int64(val)))
+	case int64:
+		return NewPodIntValue(big.NewInt(val))
+	case *big.Int:
+		if val != nil && val.Cmp(podIntMin) >= 0 && val.Cmp(podIntMax) <= 0 {
+			return NewPodIntValue(val)
+		}
+		return NewPodCryptographicValue(val)
+	case []byte:
+		return NewPodBytesValue(val)
+	case time.Time:
+		return NewPodDateValue(val)
+	default:
+		return PodValue{}, fmt.Errorf("unsupported type %T for POD value", v)
+	}
+}
+
 // Constructor for cryptographic POD values.
 // BigInt must be non-nil and in the range [PodCryptographicMin, PodCryptographicMax]
 
@@ -177,27 +249,36 @@ func (p *PodValue) checkWithNamePrefix(namePrefix string) error {
 	case PodNullValue:
 		return nil
 	case PodStringValue:
+		if MaxStringBytes > 0 && len(p.StringVal) > MaxStringBytes {
+			return fmt.Errorf("%s%s is %d bytes, more than the maximum of %d", namePrefix, p.ValueType, len(p.StringVal), MaxStringBytes)
+		}
+		if !utf8.ValidString(p.StringVal) {
+			return fmt.Errorf("%s%s is not valid UTF-8: %q", namePrefix, p.ValueType, p.StringVal)
+		}
 		return nil
 	case PodBytesValue:
 		if p.BytesVal == nil {
 			return fmt.Errorf("%s%s should not be nil", namePrefix, p.ValueType)
 		}
+		if MaxBytesLen > 0 && len(p.BytesVal) > MaxBytesLen {
+			return fmt.Errorf("%s%s is %d bytes, more than the maximum of %d", namePrefix, p.ValueType, len(p.BytesVal), MaxBytesLen)
+		}
 		return nil
 	case PodCryptographicValue:
 		return checkNumericBounds(
 			namePrefix,
 			PodCryptographicValue,
 			p.BigVal,
-			PodCryptographicMin(),
-			PodCryptographicMax(),
+			podCryptographicMin,
+			podCryptographicMax,
 		)
 	case PodIntValue:
 		return checkNumericBounds(
 			namePrefix,
 			PodIntValue,
 			p.BigVal,
-			PodIntMin(),
-			PodIntMax(),
+			podIntMin,
+			podIntMax,
 		)
 	case PodBooleanValue:
 		return nil
@@ -213,14 +294,160 @@ func (p *PodValue) checkWithNamePrefix(namePrefix string) error {
 			namePrefix,
 			PodDateValue,
 			p.TimeVal,
-			PodDateMin(),
-			PodDateMax(),
+			podDateMin,
+			podDateMax,
 		)
 	default:
 		return fmt.Errorf("%sunknown PodValueType %s", namePrefix, p.ValueType)
 	}
 }
 
+// Checks whether two values have the same type and the same value.  Values of
+// different types are never equal, even if they hold the same number.  Public
+// keys are compared after decoding, so their encoding doesn't matter.
+func (p PodValue) Equal(other PodValue) bool {
+	if p.ValueType != other.ValueType {
+		return false
+	}
+	switch p.ValueType {
+	case PodStringValue:
+		return p.StringVal == other.StringVal
+	case PodBooleanValue:
+		return p.BoolVal == other.BoolVal
+	case PodIntValue, PodCryptographicValue:
+		if p.BigVal == nil || other.BigVal == nil {
+			return p.BigVal == other.BigVal
+		}
+		return p.BigVal.Cmp(other.BigVal) == 0
+	case PodBytesValue:
+		return bytes.Equal(p.BytesVal, other.BytesVal)
+	case PodDateValue:
+		return p.TimeVal.Equal(other.TimeVal)
+	case PodEdDSAPubkeyValue:
+		a, errA := DecodeBytes(p.StringVal, 32)
+		b, errB := DecodeBytes(other.StringVal, 32)
+		if errA != nil || errB != nil {
+			return p.StringVal == other.StringVal
+		}
+		return bytes.Equal(a, b)
+	case PodNullValue:
+		return true
+	default:
+		return false
+	}
+}
+
+// Renders the type and value of this value for logging, e.g. int(321) or
+// string("foobar").
+func (p PodValue) String() string {
+	switch p.ValueType {
+	case PodNullValue:
+		return "null"
+	case PodStringValue:
+		return fmt.Sprintf("%s(%q)", p.ValueType, p.StringVal)
+	case PodBooleanValue:
+		return fmt.Sprintf("%s(%t)", p.ValueType, p.BoolVal)
+	case PodIntValue, PodCryptographicValue:
+		return fmt.Sprintf("%s(%v)", p.ValueType, p.BigVal)
+	case PodBytesValue:
+		return fmt.Sprintf("%s(%s)", p.ValueType, noPadB64.EncodeToString(p.BytesVal))
+	case PodDateValue:
+		return fmt.Sprintf("%s(%s)", p.ValueType, p.TimeVal.UTC().Format("2006-01-02T15:04:05.000Z"))
+	case PodEdDSAPubkeyValue:
+		return fmt.Sprintf("%s(%s)", p.ValueType, p.StringVal)
+	default:
+		return fmt.Sprintf("unknown(%q)", p.ValueType)
+	}
+}
+
+// Returns a copy of this value which shares no mutable state with it.
+func (p PodValue) clone() PodValue {
+	if p.BigVal != nil {
+		p.BigVal = new(big.Int).Set(p.BigVal)
+	}
+	if p.BytesVal != nil {
+		p.BytesVal = append([]byte{}, p.BytesVal...)
+	}
+	return p
+}
+
+func (p *PodValue) requireType(valueType PodValueType) error {
+	if p.ValueType != valueType {
+		return fmt.Errorf("expected %s value, got %s", valueType, p.ValueType)
+	}
+	return nil
+}
+
+// Returns the value of a string POD value, or an error for any other type.
+func (p PodValue) AsString() (string, error) {
+	if err := p.requireType(PodStringValue); err != nil {
+		return "", err
+	}
+	return p.StringVal, nil
+}
+
+// Returns a copy of the value of an int POD value, or an error for any other
+// type.
+func (p PodValue) AsInt() (*big.Int, error) {
+	if err := p.requireType(PodIntValue); err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(p.BigVal), nil
+}
+
+// Returns a copy of the value of an int or cryptographic POD value, or an
+// error for any other type.
+func (p PodValue) AsBigInt() (*big.Int, error) {
+	if p.ValueType != PodIntValue && p.ValueType != PodCryptographicValue {
+		return nil, fmt.Errorf("expected %s or %s value, got %s", PodIntValue, PodCryptographicValue, p.ValueType)
+	}
+	return new(big.Int).Set(p.BigVal), nil
+}
+
+// Returns the value of a boolean POD value, or an error for any other type.
+func (p PodValue) AsBool() (bool, error) {
+	if err := p.requireType(PodBooleanValue); err != nil {
+		return false, err
+	}
+	return p.BoolVal, nil
+}
+
+// Returns the value of a date POD value, or an error for any other type.
+func (p PodValue) AsTime() (time.Time, error) {
+	if err := p.requireType(PodDateValue); err != nil {
+		return time.Time{}, err
+	}
+	return p.TimeVal, nil
+}
+
+// Alias for AsTime.
+func (p PodValue) AsDate() (time.Time, error) {
+	return p.AsTime()
+}
+
+// Returns a copy of the value of a bytes POD value, or an error for any other
+// type.
+func (p PodValue) AsBytes() ([]byte, error) {
+	if err := p.requireType(PodBytesValue); err != nil {
+		return nil, err
+	}
+	return append([]byte{}, p.BytesVal...), nil
+}
+
+// Returns the encoded key of an eddsa_pubkey POD value, or an error for any
+// other type.
+func (p PodValue) AsEdDSAPubkey() (string, error) {
+	if err := p.requireType(PodEdDSAPubkeyValue); err != nil {
+		return "", err
+	}
+	return p.StringVal, nil
+}
+
+// Alias for AsEdDSAPubkey.
+func (p PodValue) AsPubKey() (string, error) {
+	return p.AsEdDSAPubkey()
+}
+
 func checkNumericBounds(
 	namePrefix string,
 	valueType PodValueType,
@@ -238,7 +465,7 @@ func checkNumericBounds(
 		return fmt.Errorf("%s%s %v less than minimum %v", namePrefix, valueType, val, minValue)
 	}
 	if val.Cmp(maxValue) > 0 {
-		return fmt.Errorf("%s%s %v less than minimum %v", namePrefix, valueType, val, maxValue)
+		return fmt.Errorf("%s%s %v greater than maximum %v", namePrefix, valueType, val, maxValue)
 	}
 	return nil
 }
@@ -251,10 +478,10 @@ func checkTimeBounds(
 	maxValue time.Time,
 ) error {
 	if val.Before(minValue) {
-		return fmt.Errorf("%s%s %v less than minimum %v", namePrefix, valueType, val, minValue)
+		return fmt.Errorf("%s%s %v less than minimum %v", namePrefix, valueType, val.UTC(), minValue.UTC())
 	}
 	if val.After(maxValue) {
-		return fmt.Errorf("%s%s %v less than minimum %v", namePrefix, valueType, val, maxValue)
+		return fmt.Errorf("%s%s %v greater than maximum %v", namePrefix, valueType, val.UTC(), maxValue.UTC())
 	}
 	return nil
 }
@@ -264,7 +491,11 @@ func (p *PodValue) UnmarshalJSON(data []byte) error {
 	var raw interface{}
 	var err error
 
-	if err = json.Unmarshal(data, &raw); err != nil {
+	// Decode numbers as json.Number so that large integers aren't rounded to
+	// the nearest float64.
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&raw); err != nil {
 		return err
 	}
 
@@ -276,15 +507,12 @@ func (p *PodValue) UnmarshalJSON(data []byte) error {
 		p.ValueType = PodBooleanValue
 		p.BoolVal = val
 
-	case float64:
-		// Bare number => treat as "int" by TS rules,
-		// but we store it in p.BigVal. Then we also check if it fits the standard 64-bit
-		// or do range checks as needed. We'll consider it an "int" type in Go.
+	case json.Number:
+		// Bare number => treat as "int" by TS rules, stored in p.BigVal.
+		// Range checks happen below.
 		p.ValueType = PodIntValue
-		p.BigVal = big.NewInt(int64(val))
-		// Check if fractional part was lost by int64 cast:
-		if float64(p.BigVal.Int64()) != val {
-			return fmt.Errorf("got a floating (non-integer) JSON number, which is invalid for 'int'")
+		if p.BigVal, err = parseBigIntFromNumber(val); err != nil {
+			return fmt.Errorf("invalid JSON number for 'int': %w", err)
 		}
 
 	case string:
@@ -293,6 +521,10 @@ func (p *PodValue) UnmarshalJSON(data []byte) error {
 
 	// Check for TypedJSON
 	case map[string]interface{}:
+		// Reject duplicate keys, which other parsers might resolve differently.
+		if err := checkDuplicateKeys(data, "value key"); err != nil {
+			return err
+		}
 		var jsonType string
 		var jsonValue interface{}
 
@@ -360,22 +592,29 @@ func (p *PodValue) UnmarshalJSON(data []byte) error {
 			if !ok {
 				return fmt.Errorf("invalid 'eddsa_pubkey' encoding, got %T", jsonValue)
 			}
-			p.StringVal = s
+			p.StringVal = canonicalPublicKey(s)
 
 		case "date":
 			p.ValueType = PodDateValue
+			if n, ok := jsonValue.(json.Number); ok {
+				// Some producers encode dates as milliseconds since epoch.
+				// Output is always the canonical ISO string.
+				if p.TimeVal, err = parseDateFromMillis(n); err != nil {
+					return err
+				}
+				break
+			}
 			s, ok := jsonValue.(string)
 			if !ok {
 				return fmt.Errorf("invalid 'date' encoding, got %T", jsonValue)
 			}
-			if !strings.HasSuffix(s, "Z") {
-				return fmt.Errorf("date must be encoded in UTC: %q", s)
-			}
+			// Dates with a timezone offset are converted to UTC, as
+			// NewPodDateValue does.  Output is always in UTC.
 			t, err := time.Parse(time.RFC3339, s)
 			if err != nil {
 				return fmt.Errorf("invalid date: %w", err)
 			}
-			p.TimeVal = t
+			p.TimeVal = t.Truncate(time.Millisecond).UTC()
 
 		case "null":
 			if jsonValue != nil {
@@ -396,10 +635,10 @@ func (p *PodValue) UnmarshalJSON(data []byte) error {
 
 func (p *PodValue) parseBigIntFromJSON(v interface{}) error {
 	switch vv := v.(type) {
-	case float64:
-		tmp := big.NewInt(int64(vv))
-		if float64(tmp.Int64()) != vv {
-			return fmt.Errorf("non-integer float cannot be parsed to bigint': %g", vv)
+	case json.Number:
+		tmp, err := parseBigIntFromNumber(vv)
+		if err != nil {
+			return err
 		}
 		p.BigVal = tmp
 		return nil
@@ -410,6 +649,47 @@ func (p *PodValue) parseBigIntFromJSON(v interface{}) error {
 	}
 }
 
+// Largest exponent accepted in JSON number syntax like 1e3.  No legal POD
+// value has more than 78 decimal digits, and larger exponents would be
+// expensive to expand.
+const maxJSONNumberExponent = 100
+
+// parseBigIntFromNumber parses a JSON number without losing precision.
+// Integers are parsed exactly, while fractions or exponents are accepted only
+// if the number has an exact integer value.
+func parseBigIntFromNumber(n json.Number) (*big.Int, error) {
+	s := string(n)
+	if z, ok := new(big.Int).SetString(s, 10); ok {
+		return z, nil
+	}
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(s[i+1:])
+		if err != nil || exp > maxJSONNumberExponent || exp < -maxJSONNumberExponent {
+			return nil, fmt.Errorf("JSON number exponent out of range: %s", s)
+		}
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || !r.IsInt() {
+		return nil, fmt.Errorf("non-integer JSON number cannot be parsed to bigint: %s", s)
+	}
+	return new(big.Int).Set(r.Num()), nil
+}
+
+// Parses a date from a JSON number of milliseconds since epoch, which must be
+// in the legal range for POD dates.
+func parseDateFromMillis(n json.Number) (time.Time, error) {
+	millis, err := parseBigIntFromNumber(n)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid JSON number for 'date': %w", err)
+	}
+	minMillis := big.NewInt(podDateMin.UnixMilli())
+	maxMillis := big.NewInt(podDateMax.UnixMilli())
+	if millis.Cmp(minMillis) < 0 || millis.Cmp(maxMillis) > 0 {
+		return time.Time{}, fmt.Errorf("date %v ms is outside the legal range %v to %v", millis, minMillis, maxMillis)
+	}
+	return time.UnixMilli(millis.Int64()).UTC(), nil
+}
+
 const nullHashHex = "1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d1d"
 
 // Produces the cryptographic hash which represents a POD value in ZK circuits
@@ -424,12 +704,20 @@ func (p PodValue) Hash() (*big.Int, error) {
 		}
 		return poseidon.Hash([]*big.Int{big.NewInt(0)})
 	case PodIntValue:
+		// Int64() would silently truncate a value constructed out of range,
+		// producing the hash of a different value.
+		if err := checkNumericBounds("", PodIntValue, p.BigVal, podIntMin, podIntMax); err != nil {
+			return nil, err
+		}
 		return poseidon.Hash([]*big.Int{fieldSafeInt64(p.BigVal.Int64())})
 	case PodDateValue:
 		return poseidon.Hash([]*big.Int{big.NewInt(p.TimeVal.UnixMilli())})
 	case PodBytesValue:
 		return hashBytes(p.BytesVal), nil
 	case PodCryptographicValue:
+		if err := checkNumericBounds("", PodCryptographicValue, p.BigVal, podCryptographicMin, podCryptographicMax); err != nil {
+			return nil, err
+		}
 		return poseidon.Hash([]*big.Int{p.BigVal})
 	case PodEdDSAPubkeyValue:
 		publicKeyBytes, err := DecodeBytes(p.StringVal, 32)
@@ -501,8 +789,12 @@ func (p PodValue) MarshalJSON() ([]byte, error) {
 		return json.Marshal(map[string]string{"date": iso})
 
 	case PodCryptographicValue:
+		if p.BigVal == nil {
+			return nil, fmt.Errorf("nil big.Int in PodCryptographicValue")
+		}
 		if fitsInSafeJSRange(p.BigVal) {
-			return json.Marshal(map[string]interface{}{"cryptographic": float64(p.BigVal.Int64())})
+			// Format as an integer, rather than relying on float formatting.
+			return json.Marshal(map[string]interface{}{"cryptographic": json.Number(p.BigVal.String())})
 		}
 		return json.Marshal(map[string]interface{}{"cryptographic": formatBigIntToString(p.BigVal)})
 
@@ -525,6 +817,89 @@ func (p PodValue) MarshalJSON() ([]byte, error) {
 	}
 }
 
+// Marshal this value to compact single-line text, which is the same as its
+// JSON encoding.  Implements encoding.TextMarshaler.
+func (p PodValue) MarshalText() ([]byte, error) {
+	return p.MarshalJSON()
+}
+
+// Parse a value from text produced by MarshalText, or any JSON accepted by
+// UnmarshalJSON.  Implements encoding.TextUnmarshaler.
+func (p *PodValue) UnmarshalText(text []byte) error {
+	var value PodValue
+	if err := value.UnmarshalJSON(text); err != nil {
+		return err
+	}
+	*p = value
+	return nil
+}
+
+// A value in the legacy JSON format with separate type and value fields.
+type legacyPodValue struct {
+	Type  PodValueType `json:"type"`
+	Value interface{}  `json:"value"`
+}
+
+// Marshal this value to JSON in the legacy format with separate type and value
+// fields, e.g. {"type":"int","value":321}, as accepted by UnmarshalJSON.
+func (p PodValue) marshalLegacyJSON() ([]byte, error) {
+	legacy := legacyPodValue{Type: p.ValueType}
+	switch p.ValueType {
+	case PodNullValue:
+	case PodStringValue, PodEdDSAPubkeyValue:
+		legacy.Value = p.StringVal
+	case PodBooleanValue:
+		legacy.Value = p.BoolVal
+	case PodBytesValue:
+		legacy.Value = noPadB64.EncodeToString(p.BytesVal)
+	case PodDateValue:
+		legacy.Value = p.TimeVal.UTC().Format("2006-01-02T15:04:05.000Z")
+	case PodIntValue, PodCryptographicValue:
+		if p.BigVal == nil {
+			return nil, fmt.Errorf("nil big.Int in %s", p.ValueType)
+		}
+		if fitsInSafeJSRange(p.BigVal) {
+			legacy.Value = json.Number(p.BigVal.String())
+		} else {
+			legacy.Value = formatBigIntToString(p.BigVal)
+		}
+	default:
+		return nil, fmt.Errorf("unknown PodValueType %q", p.ValueType)
+	}
+	return json.Marshal(legacy)
+}
+
+// Returns a Go value which renders this value in JSON without a type tag, for
+// display.  Numbers are rendered as decimal strings to avoid loss of precision
+// in JavaScript, bytes as Base64, and dates as ISO strings.
+func (p PodValue) displayValue() interface{} {
+	switch p.ValueType {
+	case PodStringValue, PodEdDSAPubkeyValue:
+		return p.StringVal
+	case PodBooleanValue:
+		return p.BoolVal
+	case PodIntValue, PodCryptographicValue:
+		return p.BigVal.String()
+	case PodBytesValue:
+		return noPadB64.EncodeToString(p.BytesVal)
+	case PodDateValue:
+		return p.TimeVal.UTC().Format("2006-01-02T15:04:05.000Z")
+	default:
+		return nil
+	}
+}
+
+// Returns a public key in canonical unpadded Base64, so that values built from
+// the same key are identical whether it was given as hex or Base64.  A key
+// which can't be decoded is returned unchanged, for Check to reject.
+func canonicalPublicKey(publicKey string) string {
+	publicKeyBytes, err := DecodeBytes(publicKey, 32)
+	if err != nil {
+		return publicKey
+	}
+	return noPadB64.EncodeToString(publicKeyBytes)
+}
+
 // formatBigIntToString() is called when an integer goes out of bounds of the
 // safe JS range. It returns a string representation of the integer, using hex
 // for positive values and decimal for negative values.
@@ -1,11 +1,17 @@
 package pod
 
 import (
+	"bytes"
+	"encoding"
+	"encoding/json"
 	"fmt"
 	"math"
 	"math/big"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/go-test/deep"
 )
 
 func TestNewValues(t *testing.T) {
@@ -204,7 +210,7 @@ func TestNewValues(t *testing.T) {
 	if value.ValueType != PodEdDSAPubkeyValue {
 		t.Fatalf("wrong type")
 	}
-	if value.StringVal != "c433f7a696b7aa3a5224efb3993baf0ccd9e92eecee0c29a3f6c8208a9e81d9e" {
+	if value.StringVal != "xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4" {
 		t.Fatalf("wrong value")
 	}
 
@@ -392,3 +398,670 @@ func TestDateUTCMilis(t *testing.T) {
 		t.Fatalf("POD time not the same as input: %v %v", testTime1, podTime2)
 	}
 }
+
+func TestUnmarshalDateWithOffset(t *testing.T) {
+	var offsetValue, utcValue PodValue
+	if err := json.Unmarshal([]byte(`{"date":"2025-06-30T23:44:58.123-08:00"}`), &offsetValue); err != nil {
+		t.Fatalf("failed to unmarshal date with offset: %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"date":"2025-07-01T07:44:58.123Z"}`), &utcValue); err != nil {
+		t.Fatalf("failed to unmarshal UTC date: %v", err)
+	}
+	if !offsetValue.Equal(utcValue) || offsetValue.TimeVal.Location() != time.UTC {
+		t.Fatalf("expected %v to equal %v in UTC", offsetValue, utcValue)
+	}
+	offsetHash, err := offsetValue.Hash()
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	utcHash, err := utcValue.Hash()
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	if offsetHash.Cmp(utcHash) != 0 {
+		t.Fatalf("hash %v of date with offset differs from UTC hash %v", offsetHash, utcHash)
+	}
+	data, err := json.Marshal(offsetValue)
+	if err != nil || string(data) != `{"date":"2025-07-01T07:44:58.123Z"}` {
+		t.Fatalf("expected date to marshal in UTC, got %s: %v", data, err)
+	}
+
+	// Like NewPodDateValue, precision beyond milliseconds is dropped.
+	var preciseValue PodValue
+	if err := json.Unmarshal([]byte(`{"date":"2025-06-30T23:44:58.123456789-08:00"}`), &preciseValue); err != nil {
+		t.Fatalf("failed to unmarshal precise date: %v", err)
+	}
+	if !preciseValue.Equal(utcValue) {
+		t.Fatalf("expected %v to be truncated to %v", preciseValue, utcValue)
+	}
+
+	for _, bad := range []string{`{"date":"2025-06-30T23:44:58"}`, `{"date":"2025-06-30"}`, `{"date":"2025-06-30T23:44:58+25:00"}`} {
+		var value PodValue
+		if err := json.Unmarshal([]byte(bad), &value); err == nil {
+			t.Fatalf("expected %s to fail", bad)
+		}
+	}
+}
+
+func TestNestedValuesRejected(t *testing.T) {
+	// POD values are never compound, so there is no recursive hashing to bound.
+	// Any nested array or object must be rejected while unmarshalling.
+	nestedJSON := []string{
+		`[1, 2, 3]`,
+		`{"string": {"string": "abc"}}`,
+		`{"int": [1]}`,
+		`{"type": "cryptographic", "value": {"cryptographic": 1}}`,
+		strings.Repeat("[", 1000) + strings.Repeat("]", 1000),
+		strings.Repeat(`{"a":`, 1000) + "null" + strings.Repeat("}", 1000),
+	}
+	for _, data := range nestedJSON {
+		var value PodValue
+		if err := json.Unmarshal([]byte(data), &value); err == nil {
+			t.Fatalf("expected nested value to be rejected: %s", data)
+		}
+	}
+}
+
+func TestTypedAccessors(t *testing.T) {
+	stringValue := NewPodStringValue("abc")
+	if s, err := stringValue.AsString(); err != nil || s != "abc" {
+		t.Fatalf("AsString failed: %q %v", s, err)
+	}
+	intValue, err := NewPodIntValue(big.NewInt(-5))
+	if err != nil {
+		t.Fatalf("failed to create value: %v", err)
+	}
+	if i, err := intValue.AsInt(); err != nil || i.Cmp(big.NewInt(-5)) != 0 {
+		t.Fatalf("AsInt failed: %v %v", i, err)
+	}
+	if i, err := intValue.AsBigInt(); err != nil || i.Cmp(big.NewInt(-5)) != 0 {
+		t.Fatalf("AsBigInt failed: %v %v", i, err)
+	}
+	boolValue := NewPodBooleanValue(true)
+	if b, err := boolValue.AsBool(); err != nil || !b {
+		t.Fatalf("AsBool failed: %v %v", b, err)
+	}
+	dateValue, err := NewPodDateValue(time.UnixMilli(1735689600000))
+	if err != nil {
+		t.Fatalf("failed to create value: %v", err)
+	}
+	if d, err := dateValue.AsTime(); err != nil || !d.Equal(time.UnixMilli(1735689600000)) {
+		t.Fatalf("AsTime failed: %v %v", d, err)
+	}
+	if d, err := dateValue.AsDate(); err != nil || !d.Equal(time.UnixMilli(1735689600000)) {
+		t.Fatalf("AsDate failed: %v %v", d, err)
+	}
+	bytesValue, err := NewPodBytesValue([]byte{1, 2, 3})
+	if err != nil {
+		t.Fatalf("failed to create value: %v", err)
+	}
+	if b, err := bytesValue.AsBytes(); err != nil || len(b) != 3 {
+		t.Fatalf("AsBytes failed: %v %v", b, err)
+	} else {
+		b[0] = 9
+	}
+	if bytesValue.BytesVal[0] != 1 {
+		t.Fatalf("AsBytes should return a copy")
+	}
+	if i, err := intValue.AsInt(); err == nil {
+		i.SetInt64(7)
+	}
+	if intValue.BigVal.Int64() != -5 {
+		t.Fatalf("AsInt should return a copy")
+	}
+	pubKeyValue, err := NewPodEdDSAPubkeyValue("xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4")
+	if err != nil {
+		t.Fatalf("failed to create value: %v", err)
+	}
+	if k, err := pubKeyValue.AsEdDSAPubkey(); err != nil || k != "xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4" {
+		t.Fatalf("AsEdDSAPubkey failed: %v %v", k, err)
+	}
+	if k, err := pubKeyValue.AsPubKey(); err != nil || k != "xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4" {
+		t.Fatalf("AsPubKey failed: %v %v", k, err)
+	}
+
+	// Every accessor should reject a value of another type.
+	nullValue := NewPodNullValue()
+	if _, err := nullValue.AsString(); err == nil {
+		t.Fatalf("expected AsString to fail on null")
+	}
+	if _, err := stringValue.AsInt(); err == nil {
+		t.Fatalf("expected AsInt to fail on string")
+	}
+	cryptValue, err := NewPodCryptographicValue(big.NewInt(5))
+	if err != nil {
+		t.Fatalf("failed to create value: %v", err)
+	}
+	if _, err := cryptValue.AsInt(); err == nil {
+		t.Fatalf("expected AsInt to fail on cryptographic")
+	}
+	if i, err := cryptValue.AsBigInt(); err != nil || i.Cmp(big.NewInt(5)) != 0 {
+		t.Fatalf("AsBigInt failed on cryptographic: %v %v", i, err)
+	}
+	if _, err := boolValue.AsBigInt(); err == nil {
+		t.Fatalf("expected AsBigInt to fail on boolean")
+	}
+	if _, err := intValue.AsBool(); err == nil {
+		t.Fatalf("expected AsBool to fail on int")
+	}
+	if _, err := boolValue.AsTime(); err == nil {
+		t.Fatalf("expected AsTime to fail on boolean")
+	}
+	if _, err := boolValue.AsDate(); err == nil {
+		t.Fatalf("expected AsDate to fail on boolean")
+	}
+	if _, err := pubKeyValue.AsBytes(); err == nil {
+		t.Fatalf("expected AsBytes to fail on eddsa_pubkey")
+	}
+	if _, err := stringValue.AsEdDSAPubkey(); err == nil {
+		t.Fatalf("expected AsEdDSAPubkey to fail on string")
+	}
+	if _, err := stringValue.AsPubKey(); err == nil {
+		t.Fatalf("expected AsPubKey to fail on string")
+	}
+}
+
+func TestUnmarshalLargeJSONNumbers(t *testing.T) {
+	// Integers at and beyond 2^53 can't all be represented by a float64, so
+	// they must parse exactly.
+	testCases := []struct {
+		json      string
+		valueType PodValueType
+		expected  string
+	}{
+		{`9007199254740991`, PodIntValue, "9007199254740991"},
+		{`9007199254740992`, PodIntValue, "9007199254740992"},
+		{`9007199254740993`, PodIntValue, "9007199254740993"},
+		{`-9007199254740993`, PodIntValue, "-9007199254740993"},
+		{`{"int":9007199254740993}`, PodIntValue, "9007199254740993"},
+		{`{"type":"int","value":9007199254740993}`, PodIntValue, "9007199254740993"},
+		{`{"cryptographic":9007199254740993}`, PodCryptographicValue, "9007199254740993"},
+		{`1e3`, PodIntValue, "1000"},
+		{`12.0`, PodIntValue, "12"},
+	}
+	for _, tc := range testCases {
+		var value PodValue
+		if err := json.Unmarshal([]byte(tc.json), &value); err != nil {
+			t.Fatalf("failed to unmarshal %s: %v", tc.json, err)
+		}
+		if value.ValueType != tc.valueType || value.BigVal.String() != tc.expected {
+			t.Fatalf("unmarshalled %s as %s %v, expected %s %s", tc.json, value.ValueType, value.BigVal, tc.valueType, tc.expected)
+		}
+	}
+
+	// Large values round-trip through the explicit string form.
+	var value PodValue
+	if err := json.Unmarshal([]byte(`9007199254740993`), &value); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	serialized, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	if string(serialized) != `{"int":"0x20000000000001"}` {
+		t.Fatalf("unexpected serialization %s", serialized)
+	}
+
+	for _, data := range []string{`1.5`, `{"int":1.5}`, `{"cryptographic":1e-1}`, `1e1000000000`} {
+		if err := json.Unmarshal([]byte(data), &value); err == nil {
+			t.Fatalf("expected %s to fail to unmarshal", data)
+		}
+	}
+}
+
+func TestMarshalSafeCryptographicValues(t *testing.T) {
+	testCases := map[int64]string{
+		0:                `{"cryptographic":0}`,
+		1234567890:       `{"cryptographic":1234567890}`,
+		1e15:             `{"cryptographic":1000000000000000}`,
+		9007199254740990: `{"cryptographic":9007199254740990}`,
+		9007199254740991: `{"cryptographic":9007199254740991}`,
+		9007199254740992: `{"cryptographic":"0x20000000000000"}`,
+	}
+	for val, expected := range testCases {
+		value, err := NewPodCryptographicValue(big.NewInt(val))
+		if err != nil {
+			t.Fatalf("failed to create value: %v", err)
+		}
+		serialized, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		}
+		if string(serialized) != expected {
+			t.Fatalf("marshalled %d as %s, expected %s", val, serialized, expected)
+		}
+	}
+}
+
+func TestBytesEncoding(t *testing.T) {
+	// Expected strings match Base64 output of @pcd/pod, which is standard
+	// Base64 with padding removed.
+	testCases := map[string][]byte{
+		`{"bytes":""}`:     {},
+		`{"bytes":"AA"}`:   {0},
+		`{"bytes":"AAH/"}`: {0, 1, 255},
+		`{"bytes":"+/8"}`:  {0xfb, 0xff},
+	}
+	for expected, val := range testCases {
+		value, err := NewPodBytesValue(val)
+		if err != nil {
+			t.Fatalf("failed to create value: %v", err)
+		}
+		serialized, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		}
+		if string(serialized) != expected {
+			t.Fatalf("marshalled %v as %s, expected %s", val, serialized, expected)
+		}
+
+		var parsed PodValue
+		if err := json.Unmarshal(serialized, &parsed); err != nil {
+			t.Fatalf("failed to unmarshal %s: %v", serialized, err)
+		}
+		if parsed.ValueType != PodBytesValue || !bytes.Equal(parsed.BytesVal, val) {
+			t.Fatalf("round trip of %v produced %v", val, parsed.BytesVal)
+		}
+	}
+
+	// Padded input from other encoders is still accepted.
+	var parsed PodValue
+	if err := json.Unmarshal([]byte(`{"bytes":"AA=="}`), &parsed); err != nil || !bytes.Equal(parsed.BytesVal, []byte{0}) {
+		t.Fatalf("failed to unmarshal padded bytes: %v", err)
+	}
+}
+
+func TestUnmarshalDateMillis(t *testing.T) {
+	var fromMillis, fromString PodValue
+	if err := json.Unmarshal([]byte(`{"date":1735689600000}`), &fromMillis); err != nil {
+		t.Fatalf("failed to unmarshal date millis: %v", err)
+	}
+	if err := json.Unmarshal([]byte(`{"date":"2025-01-01T00:00:00.000Z"}`), &fromString); err != nil {
+		t.Fatalf("failed to unmarshal date string: %v", err)
+	}
+	if fromMillis.ValueType != PodDateValue || !fromMillis.TimeVal.Equal(fromString.TimeVal) {
+		t.Fatalf("expected millis to match ISO date, got %v", fromMillis.TimeVal)
+	}
+	serialized, err := json.Marshal(fromMillis)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	if string(serialized) != `{"date":"2025-01-01T00:00:00.000Z"}` {
+		t.Fatalf("expected canonical ISO output, got %s", serialized)
+	}
+
+	var boundary PodValue
+	if err := json.Unmarshal([]byte(`{"date":8640000000000000}`), &boundary); err != nil {
+		t.Fatalf("failed to unmarshal maximum date: %v", err)
+	}
+	for _, input := range []string{
+		`{"date":8640000000000001}`,
+		`{"date":-8640000000000001}`,
+		`{"date":1e30}`,
+		`{"date":1.5}`,
+	} {
+		var value PodValue
+		if err := json.Unmarshal([]byte(input), &value); err == nil {
+			t.Fatalf("expected %s to fail", input)
+		}
+	}
+}
+
+func TestValueEqual(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	equalPairs := [][2]PodValue{
+		{NewPodStringValue("a"), NewPodStringValue("a")},
+		{NewPodBooleanValue(true), NewPodBooleanValue(true)},
+		{{ValueType: PodIntValue, BigVal: big.NewInt(5)}, {ValueType: PodIntValue, BigVal: big.NewInt(5)}},
+		{{ValueType: PodCryptographicValue, BigVal: big.NewInt(5)}, {ValueType: PodCryptographicValue, BigVal: big.NewInt(5)}},
+		{{ValueType: PodBytesValue, BytesVal: []byte{1, 2}}, {ValueType: PodBytesValue, BytesVal: []byte{1, 2}}},
+		{{ValueType: PodDateValue, TimeVal: date}, {ValueType: PodDateValue, TimeVal: date.In(time.FixedZone("X", 3600))}},
+		{{ValueType: PodEdDSAPubkeyValue, StringVal: "xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4"}, {ValueType: PodEdDSAPubkeyValue, StringVal: "c433f7a696b7aa3a5224efb3993baf0ccd9e92eecee0c29a3f6c8208a9e81d9e"}},
+		{NewPodNullValue(), NewPodNullValue()},
+	}
+	for _, pair := range equalPairs {
+		if !pair[0].Equal(pair[1]) || !pair[1].Equal(pair[0]) {
+			t.Fatalf("expected %v to equal %v", pair[0], pair[1])
+		}
+	}
+
+	unequalPairs := [][2]PodValue{
+		{NewPodStringValue("a"), NewPodStringValue("b")},
+		{NewPodBooleanValue(true), NewPodBooleanValue(false)},
+		{{ValueType: PodIntValue, BigVal: big.NewInt(5)}, {ValueType: PodIntValue, BigVal: big.NewInt(6)}},
+		{{ValueType: PodIntValue, BigVal: big.NewInt(5)}, {ValueType: PodCryptographicValue, BigVal: big.NewInt(5)}},
+		{{ValueType: PodBytesValue, BytesVal: []byte{1, 2}}, {ValueType: PodBytesValue, BytesVal: []byte{1}}},
+		{{ValueType: PodDateValue, TimeVal: date}, {ValueType: PodDateValue, TimeVal: date.Add(time.Millisecond)}},
+		{NewPodStringValue(""), NewPodNullValue()},
+	}
+	for _, pair := range unequalPairs {
+		if pair[0].Equal(pair[1]) || pair[1].Equal(pair[0]) {
+			t.Fatalf("expected %v not to equal %v", pair[0], pair[1])
+		}
+	}
+}
+
+func TestValueString(t *testing.T) {
+	testCases := map[string]PodValue{
+		`int(321)`:                       {ValueType: PodIntValue, BigVal: big.NewInt(321)},
+		`cryptographic(123)`:             {ValueType: PodCryptographicValue, BigVal: big.NewInt(123)},
+		`string("foobar")`:               NewPodStringValue("foobar"),
+		`boolean(false)`:                 NewPodBooleanValue(false),
+		`bytes(AQID)`:                    {ValueType: PodBytesValue, BytesVal: []byte{1, 2, 3}},
+		`date(2025-01-01T00:00:00.000Z)`: {ValueType: PodDateValue, TimeVal: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		`eddsa_pubkey(xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4)`: {ValueType: PodEdDSAPubkeyValue, StringVal: "xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4"},
+		`null`: NewPodNullValue(),
+	}
+	for expected, value := range testCases {
+		if value.String() != expected {
+			t.Fatalf("expected %s, got %s", expected, value.String())
+		}
+		if fmt.Sprintf("%v", value) != expected {
+			t.Fatalf("expected %%v to use String, got %v", value)
+		}
+	}
+}
+
+func TestBoundsAreCopies(t *testing.T) {
+	PodCryptographicMax().SetInt64(0)
+	PodIntMax().SetInt64(0)
+	PodIntMin().SetInt64(0)
+	if _, err := NewPodCryptographicValue(big.NewInt(1)); err != nil {
+		t.Fatalf("modifying a returned bound changed the shared bound: %v", err)
+	}
+	if _, err := NewPodIntValue(big.NewInt(-1)); err != nil {
+		t.Fatalf("modifying a returned bound changed the shared bound: %v", err)
+	}
+	if PodCryptographicMax().Cmp(podCryptographicMax) != 0 || PodIntMax().Int64() != math.MaxInt64 {
+		t.Fatalf("bounds changed")
+	}
+}
+
+func BenchmarkCheckValues(b *testing.B) {
+	value := PodValue{ValueType: PodCryptographicValue, BigVal: big.NewInt(1234567890)}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := value.Check(); err != nil {
+			b.Fatalf("Check failed: %v", err)
+		}
+	}
+}
+
+func TestHashOutOfRangeValues(t *testing.T) {
+	// 2^64 + 5 would truncate to 5 with Int64(), so must not hash like 5.
+	outOfRange := PodValue{ValueType: PodIntValue, BigVal: new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(5))}
+	if _, err := outOfRange.Hash(); err == nil {
+		t.Fatalf("expected out of range int to fail to hash")
+	}
+	if _, err := (PodValue{ValueType: PodIntValue}).Hash(); err == nil {
+		t.Fatalf("expected nil int to fail to hash")
+	}
+	if _, err := (PodValue{ValueType: PodCryptographicValue}).Hash(); err == nil {
+		t.Fatalf("expected nil cryptographic to fail to hash")
+	}
+	if _, err := (PodValue{ValueType: PodCryptographicValue, BigVal: big.NewInt(-1)}).Hash(); err == nil {
+		t.Fatalf("expected negative cryptographic to fail to hash")
+	}
+
+	inRange := PodValue{ValueType: PodIntValue, BigVal: PodIntMin()}
+	if _, err := inRange.Hash(); err != nil {
+		t.Fatalf("expected minimum int to hash: %v", err)
+	}
+}
+
+func TestUnmarshalInt64BoundaryJSONNumbers(t *testing.T) {
+	// Bare integers up to the int64 limits must parse exactly, not via float64,
+	// which would round them to 2^63 and fail the range check.
+	for _, data := range []string{`9223372036854775807`, `-9223372036854775808`, `9223372036854775806`} {
+		var value PodValue
+		if err := json.Unmarshal([]byte(data), &value); err != nil {
+			t.Fatalf("failed to unmarshal %s: %v", data, err)
+		}
+		if value.BigVal.String() != data {
+			t.Fatalf("unmarshalled %s as %v", data, value.BigVal)
+		}
+	}
+	var value PodValue
+	if err := json.Unmarshal([]byte(`9223372036854775808`), &value); err == nil {
+		t.Fatalf("expected int above maximum to fail")
+	}
+}
+
+func TestBoundsErrorMessages(t *testing.T) {
+	testCases := []struct {
+		value    PodValue
+		expected string
+	}{
+		{
+			PodValue{ValueType: PodIntValue, BigVal: new(big.Int).Sub(PodIntMin(), big.NewInt(1))},
+			"int -9223372036854775809 less than minimum -9223372036854775808",
+		},
+		{
+			PodValue{ValueType: PodIntValue, BigVal: new(big.Int).Add(PodIntMax(), big.NewInt(1))},
+			"int 9223372036854775808 greater than maximum 9223372036854775807",
+		},
+		{
+			PodValue{ValueType: PodCryptographicValue, BigVal: big.NewInt(-1)},
+			"cryptographic -1 less than minimum 0",
+		},
+		{
+			PodValue{ValueType: PodCryptographicValue, BigVal: new(big.Int).Add(PodCryptographicMax(), big.NewInt(1))},
+			"cryptographic 21888242871839275222246405745257275088548364400416034343698204186575808495617 greater than maximum 21888242871839275222246405745257275088548364400416034343698204186575808495616",
+		},
+		{
+			PodValue{ValueType: PodDateValue, TimeVal: PodDateMin().Add(-time.Millisecond)},
+			"date -271821-04-19 23:59:59.999 +0000 UTC less than minimum -271821-04-20 00:00:00 +0000 UTC",
+		},
+		{
+			PodValue{ValueType: PodDateValue, TimeVal: PodDateMax().Add(time.Millisecond)},
+			"date 275760-09-13 00:00:00.001 +0000 UTC greater than maximum 275760-09-13 00:00:00 +0000 UTC",
+		},
+	}
+	for _, tc := range testCases {
+		err := tc.value.Check()
+		if err == nil || err.Error() != tc.expected {
+			t.Fatalf("expected error %q, got %v", tc.expected, err)
+		}
+	}
+}
+
+func TestBytesValueIsCopied(t *testing.T) {
+	buffer := []byte{1, 2, 3}
+	value, err := NewPodBytesValue(buffer)
+	if err != nil {
+		t.Fatalf("failed to create value: %v", err)
+	}
+	hashBefore, err := value.Hash()
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	buffer[0] = 9
+	if value.BytesVal[0] != 1 {
+		t.Fatalf("modifying the input buffer changed the value")
+	}
+	hashAfter, err := value.Hash()
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	if hashBefore.Cmp(hashAfter) != 0 {
+		t.Fatalf("modifying the input buffer changed the hash")
+	}
+
+	output, err := value.AsBytes()
+	if err != nil {
+		t.Fatalf("AsBytes failed: %v", err)
+	}
+	output[1] = 9
+	if value.BytesVal[1] != 2 {
+		t.Fatalf("modifying the output of AsBytes changed the value")
+	}
+}
+
+func TestStringValueNotNormalized(t *testing.T) {
+	// Strings are hashed as given, matching @pcd/pod, so composed and
+	// decomposed forms of the same text are different values.
+	composed := NewPodStringValue("caf\u00e9")
+	decomposed := NewPodStringValue("cafe\u0301")
+	if decomposed.StringVal != "cafe\u0301" {
+		t.Fatalf("expected constructor to keep the string unchanged, got %q", decomposed.StringVal)
+	}
+	if composed.Equal(decomposed) {
+		t.Fatalf("expected composed and decomposed strings to differ")
+	}
+	composedHash, err := composed.Hash()
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	decomposedHash, err := decomposed.Hash()
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	if composedHash.Cmp(decomposedHash) == 0 {
+		t.Fatalf("expected composed and decomposed strings to hash differently")
+	}
+	if decomposedHash.Cmp(hashString("cafe\u0301")) != 0 {
+		t.Fatalf("expected string to hash as its raw UTF-8 bytes")
+	}
+
+	// A value parsed from JSON is the same as one constructed in Go.
+	var parsed PodValue
+	if err := json.Unmarshal([]byte(`"cafe\u0301"`), &parsed); err != nil {
+		t.Fatalf("failed to unmarshal string: %v", err)
+	}
+	if !parsed.Equal(decomposed) {
+		t.Fatalf("expected parsed %v to equal constructed %v", parsed, decomposed)
+	}
+}
+
+func TestInvalidUTF8StringRejected(t *testing.T) {
+	// 0xc3 starts a two-byte sequence, but 0x28 isn't a continuation byte.
+	value := NewPodStringValue("a\xc3\x28b")
+	if err := value.Check(); err == nil || !strings.Contains(err.Error(), "not valid UTF-8") {
+		t.Fatalf("expected invalid UTF-8 error, got %v", err)
+	}
+	if _, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{"name": value}); err == nil {
+		t.Fatalf("expected CreatePod to reject invalid UTF-8")
+	}
+	valid := NewPodStringValue("caf\u00e9 \U0001F600")
+	if err := valid.Check(); err != nil {
+		t.Fatalf("expected valid UTF-8 to pass: %v", err)
+	}
+}
+
+func TestNewPodValue(t *testing.T) {
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	big128 := new(big.Int).Lsh(big.NewInt(1), 128)
+	for _, tc := range []struct {
+		input    interface{}
+		expected PodValue
+	}{
+		{nil, NewPodNullValue()},
+		{"hello", NewPodStringValue("hello")},
+		{true, NewPodBooleanValue(true)},
+		{42, PodValue{ValueType: PodIntValue, BigVal: big.NewInt(42)}},
+		{int64(-42), PodValue{ValueType: PodIntValue, BigVal: big.NewInt(-42)}},
+		{big.NewInt(7), PodValue{ValueType: PodIntValue, BigVal: big.NewInt(7)}},
+		{big128, PodValue{ValueType: PodCryptographicValue, BigVal: big128}},
+		{[]byte{1, 2}, PodValue{ValueType: PodBytesValue, BytesVal: []byte{1, 2}}},
+		{date, PodValue{ValueType: PodDateValue, TimeVal: date}},
+		{NewPodBooleanValue(false), NewPodBooleanValue(false)},
+	} {
+		value, err := NewPodValue(tc.input)
+		if err != nil {
+			t.Fatalf("NewPodValue(%v) failed: %v", tc.input, err)
+		}
+		if !value.Equal(tc.expected) {
+			t.Fatalf("NewPodValue(%v) is %v, expected %v", tc.input, value, tc.expected)
+		}
+	}
+
+	for _, input := range []interface{}{
+		1.5,
+		[]string{"a"},
+		map[string]interface{}{},
+		int32(1),
+		(*big.Int)(nil),
+		new(big.Int).Neg(big128),
+		new(big.Int).Lsh(big.NewInt(1), 256),
+		[]byte(nil),
+		PodValue{ValueType: PodIntValue},
+	} {
+		if _, err := NewPodValue(input); err == nil {
+			t.Fatalf("expected NewPodValue(%#v) to fail", input)
+		}
+	}
+}
+
+func TestCanonicalPublicKey(t *testing.T) {
+	const base64Key = "xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4"
+	canonical := PodValue{ValueType: PodEdDSAPubkeyValue, StringVal: base64Key}
+	expected := `{"eddsa_pubkey":"` + base64Key + `"}`
+	for _, key := range []string{base64Key, base64Key + "=", "c433f7a696b7aa3a5224efb3993baf0ccd9e92eecee0c29a3f6c8208a9e81d9e"} {
+		value, err := NewPodEdDSAPubkeyValue(key)
+		if err != nil {
+			t.Fatalf("NewPodEdDSAPubkeyValue failed: %v", err)
+		}
+		var unmarshalled PodValue
+		if err := json.Unmarshal([]byte(`{"eddsa_pubkey":"`+key+`"}`), &unmarshalled); err != nil {
+			t.Fatalf("failed to unmarshal value: %v", err)
+		}
+		for _, value := range []PodValue{value, unmarshalled} {
+			if !value.Equal(canonical) || value.String() != canonical.String() {
+				t.Fatalf("expected %s to equal %v, got %v", key, canonical, value)
+			}
+			if m := (PodEntries{"pk": value}).ToMap(); m["pk"] != base64Key {
+				t.Fatalf("expected %s in map as %s, got %v", key, base64Key, m["pk"])
+			}
+			data, err := json.Marshal(value)
+			if err != nil {
+				t.Fatalf("failed to marshal value: %v", err)
+			}
+			if string(data) != expected {
+				t.Fatalf("expected %s to marshal as %s, got %s", key, expected, data)
+			}
+			data, err = value.marshalLegacyJSON()
+			if err != nil || !strings.Contains(string(data), `"value":"`+base64Key+`"`) {
+				t.Fatalf("expected %s to marshal in legacy format as Base64, got %s: %v", key, data, err)
+			}
+		}
+	}
+}
+
+func TestValueText(t *testing.T) {
+	values := []PodValue{
+		NewPodNullValue(),
+		NewPodStringValue("hello world"),
+		NewPodStringValue("123"),
+		NewPodBooleanValue(false),
+		{ValueType: PodIntValue, BigVal: big.NewInt(-42)},
+		{ValueType: PodIntValue, BigVal: PodIntMax()},
+		{ValueType: PodCryptographicValue, BigVal: big.NewInt(42)},
+		{ValueType: PodCryptographicValue, BigVal: PodCryptographicMax()},
+		{ValueType: PodBytesValue, BytesVal: []byte{}},
+		{ValueType: PodBytesValue, BytesVal: []byte("line\nbreak")},
+		{ValueType: PodDateValue, TimeVal: time.Date(2025, 1, 1, 0, 0, 0, 123e6, time.UTC)},
+		{ValueType: PodEdDSAPubkeyValue, StringVal: "xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4"},
+	}
+	for _, value := range values {
+		var _ encoding.TextMarshaler = value
+		text, err := value.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText failed: %v", err)
+		}
+		if bytes.ContainsAny(text, "\r\n") {
+			t.Fatalf("expected single-line text, got %q", text)
+		}
+		parsed := PodValue{ValueType: PodStringValue, StringVal: "leftover", BigVal: big.NewInt(1)}
+		if err := parsed.UnmarshalText(text); err != nil {
+			t.Fatalf("UnmarshalText(%s) failed: %v", text, err)
+		}
+		if diff := deep.Equal(parsed, value); diff != nil {
+			t.Fatalf("text %s didn't round trip: %v", text, diff)
+		}
+	}
+
+	var value PodValue
+	if err := value.UnmarshalText([]byte("not a value")); err == nil {
+		t.Fatalf("expected invalid text to fail")
+	}
+}
@@ -0,0 +1,109 @@
+package pod
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Name of the cryptosuite used in the proof of a Verifiable Credential which
+// wraps a POD.  This isn't a new signature scheme: the proof value is the
+// POD's own EdDSA-Poseidon signature on the BabyJubJub curve, over the content
+// ID of the entries in credentialSubject.
+const VCCryptosuite = "eddsa-poseidon-babyjubjub-pod"
+
+// JSON-LD context for W3C Verifiable Credentials Data Model v2.0
+const vcContext = "https://www.w3.org/ns/credentials/v2"
+
+// Options for exporting a POD as a Verifiable Credential.
+type VCOptions struct {
+	// Optional identifier for the credential.
+	ID string
+
+	// Identifier of the issuer.  Defaults to a URN containing the POD's
+	// signer public key.
+	Issuer string
+
+	// Additional credential types, after "VerifiableCredential".
+	Types []string
+
+	// Optional time from which the credential is valid.
+	ValidFrom time.Time
+}
+
+type verifiableCredential struct {
+	Context           []string   `json:"@context"`
+	ID                string     `json:"id,omitempty"`
+	Type              []string   `json:"type"`
+	Issuer            string     `json:"issuer"`
+	ValidFrom         string     `json:"validFrom,omitempty"`
+	CredentialSubject PodEntries `json:"credentialSubject"`
+	Proof             vcProof    `json:"proof"`
+}
+
+type vcProof struct {
+	Type               string `json:"type"`
+	Cryptosuite        string `json:"cryptosuite"`
+	ProofPurpose       string `json:"proofPurpose"`
+	VerificationMethod string `json:"verificationMethod"`
+	ProofValue         string `json:"proofValue"`
+}
+
+// Export this POD as a W3C Verifiable Credential in JSON.  The entries become
+// credentialSubject claims in POD's terse JSON format, and the signature and
+// signer public key are carried in a DataIntegrityProof using VCCryptosuite.
+func (p *Pod) ToVerifiableCredential(opts VCOptions) ([]byte, error) {
+	if err := p.CheckFormat(); err != nil {
+		return nil, err
+	}
+
+	issuer := opts.Issuer
+	if issuer == "" {
+		issuer = "urn:eddsa_pubkey:" + p.SignerPublicKey
+	}
+	var validFrom string
+	if !opts.ValidFrom.IsZero() {
+		validFrom = opts.ValidFrom.UTC().Format(time.RFC3339)
+	}
+
+	return json.Marshal(verifiableCredential{
+		Context:           []string{vcContext},
+		ID:                opts.ID,
+		Type:              append([]string{"VerifiableCredential"}, opts.Types...),
+		Issuer:            issuer,
+		ValidFrom:         validFrom,
+		CredentialSubject: p.Entries,
+		Proof: vcProof{
+			Type:               "DataIntegrityProof",
+			Cryptosuite:        VCCryptosuite,
+			ProofPurpose:       "assertionMethod",
+			VerificationMethod: p.SignerPublicKey,
+			ProofValue:         p.Signature,
+		},
+	})
+}
+
+// Parse a POD from a Verifiable Credential produced by ToVerifiableCredential.
+// The POD's format is checked, but its signature is not verified.
+func FromVerifiableCredential(data []byte) (*Pod, error) {
+	var vc verifiableCredential
+	if err := json.Unmarshal(data, &vc); err != nil {
+		return nil, err
+	}
+	if vc.Proof.Type != "DataIntegrityProof" || vc.Proof.Cryptosuite != VCCryptosuite {
+		return nil, fmt.Errorf("unsupported credential proof %s/%s", vc.Proof.Type, vc.Proof.Cryptosuite)
+	}
+	if vc.CredentialSubject == nil {
+		return nil, fmt.Errorf("credential has no credentialSubject")
+	}
+
+	pod := &Pod{
+		Entries:         vc.CredentialSubject,
+		Signature:       vc.Proof.ProofValue,
+		SignerPublicKey: vc.Proof.VerificationMethod,
+	}
+	if err := pod.checkFormatWithoutEntries(); err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
@@ -0,0 +1,58 @@
+package pod
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+func TestVerifiableCredential(t *testing.T) {
+	pod, err := CreatePod("0001020304050607080900010203040506070809000102030405060708090001", PodEntries{
+		"name":  NewPodStringValue("Alice"),
+		"level": PodValue{ValueType: PodIntValue, BigVal: big.NewInt(3)},
+	})
+	if err != nil {
+		t.Fatalf("CreatePod failed: %v", err)
+	}
+
+	vcJSON, err := pod.ToVerifiableCredential(VCOptions{
+		ID:        "urn:uuid:8209fd10-667d-4524-a855-acc51ce795f3",
+		Types:     []string{"MembershipCredential"},
+		ValidFrom: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("ToVerifiableCredential failed: %v", err)
+	}
+	expectedVC := `{"@context":["https://www.w3.org/ns/credentials/v2"],"id":"urn:uuid:8209fd10-667d-4524-a855-acc51ce795f3","type":["VerifiableCredential","MembershipCredential"],"issuer":"urn:eddsa_pubkey:xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4","validFrom":"2025-01-01T00:00:00Z","credentialSubject":{"level":3,"name":"Alice"},"proof":{"type":"DataIntegrityProof","cryptosuite":"eddsa-poseidon-babyjubjub-pod","proofPurpose":"assertionMethod","verificationMethod":"xDP3ppa3qjpSJO+zmTuvDM2eku7O4MKaP2yCCKnoHZ4","proofValue":"` + pod.Signature + `"}}`
+	if string(vcJSON) != expectedVC {
+		t.Fatalf("unexpected credential: %s", vcJSON)
+	}
+
+	parsed, err := FromVerifiableCredential(vcJSON)
+	if err != nil {
+		t.Fatalf("FromVerifiableCredential failed: %v", err)
+	}
+	if diff := deep.Equal(pod, parsed); diff != nil {
+		t.Fatalf("Original and parsed PODs differ: %v", diff)
+	}
+	ok, err := parsed.Verify()
+	if err != nil || !ok {
+		t.Fatalf("parsed POD failed to verify: %v", err)
+	}
+
+	var vc map[string]interface{}
+	if err := json.Unmarshal(vcJSON, &vc); err != nil {
+		t.Fatalf("failed to unmarshal credential: %v", err)
+	}
+	vc["proof"].(map[string]interface{})["cryptosuite"] = "eddsa-rdfc-2022"
+	otherVC, err := json.Marshal(vc)
+	if err != nil {
+		t.Fatalf("failed to marshal credential: %v", err)
+	}
+	if _, err := FromVerifiableCredential(otherVC); err == nil {
+		t.Fatalf("expected unsupported cryptosuite to fail")
+	}
+}
@@ -1,46 +1,159 @@
 package pod
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
+	"strings"
+	"time"
 
 	"github.com/iden3/go-iden3-crypto/v2/babyjub"
+	"github.com/iden3/go-iden3-crypto/v2/poseidon"
+	"github.com/iden3/go-iden3-crypto/v2/utils"
+)
+
+var (
+	// A POD's signature doesn't match its entries and signer.
+	ErrSignatureMismatch = errors.New("POD signature does not match")
+
+	// A POD is missing an entry required by a verification check.
+	ErrMissingEntry = errors.New("POD entry is missing")
+
+	// A POD entry required by a verification check has the wrong type.
+	ErrWrongEntryType = errors.New("POD entry has the wrong type")
+
+	// A POD was signed by a key which isn't trusted by the verifier.
+	ErrUntrustedSigner = errors.New("POD signer is not trusted")
+
+	// A POD is missing its expiration date entry.  Wraps ErrMissingEntry.
+	ErrMissingExpiry = fmt.Errorf("%w: no expiration date", ErrMissingEntry)
+
+	// A POD's expiration date has passed.
+	ErrExpired = errors.New("POD has expired")
+
+	// A POD entry holds a value outside the set allowed by the verifier.
+	ErrValueNotAllowed = errors.New("POD entry value is not allowed")
+
+	// A signature's S component isn't reduced modulo the subgroup order.
+	ErrNonCanonicalSignature = errors.New("signature is not canonical")
+
+	// A private key can't be decoded.
+	ErrInvalidPrivateKey = errors.New("invalid private key")
+
+	// A signature can't be decoded, or isn't a valid curve point and scalar.
+	ErrMalformedSignature = errors.New("malformed signature")
+
+	// A public key can't be decoded, or isn't a valid curve point.
+	ErrMalformedPublicKey = errors.New("malformed public key")
 )
 
 // Cryptographically verify the contents of this POD.  This involves hashing
 // all of its entries to generate a Content ID, then verifying the signature
 // on the Content ID.
 func (p *Pod) Verify() (bool, error) {
-	// Validate and decode signature format
-	signatureBytes, err := DecodeBytes(p.Signature, 64)
-	if err != nil || len(signatureBytes) != 64 {
-		return false, fmt.Errorf("failed to decode signature: %w", err)
+	publicKey, err := decodePublicKey(p.SignerPublicKey)
+	if err != nil {
+		return false, err
 	}
+	return VerifyWithPublicKey(p.Entries, p.Signature, publicKey)
+}
 
-	// Validate and decode public key format
-	publicKeyBytes, err := DecodeBytes(p.SignerPublicKey, 32)
-	if err != nil || len(publicKeyBytes) != 32 {
-		return false, fmt.Errorf("failed to decode signer public key: %w", err)
+// Parse a POD from JSON and cryptographically verify it.  A POD which can't be
+// parsed produces an error wrapping ErrMalformedPod, while a well-formed POD
+// whose signature doesn't match produces false with no error.
+func VerifyJSON(podJSON []byte) (bool, error) {
+	var p Pod
+	if err := json.Unmarshal(podJSON, &p); err != nil {
+		return false, fmt.Errorf("%w: %w", ErrMalformedPod, err)
 	}
+	return p.Verify()
+}
 
-	contentID, err := computeContentID(p.Entries)
+// Cryptographically verify a signature on the given entries by an already
+// decompressed public key.  This is equivalent to Verify, but lets callers who
+// verify many PODs from the same signer skip decompressing the key each time.
+func VerifyWithPublicKey(entries PodEntries, signature string, publicKey *babyjub.PublicKey) (bool, error) {
+	if publicKey == nil {
+		return false, fmt.Errorf("public key should not be nil")
+	}
+	contentID, err := ComputeContentID(entries)
 	if err != nil {
 		return false, fmt.Errorf("failed computing content ID: %w", err)
 	}
+	return verifyContentIDSignature(contentID, signature, publicKey)
+}
 
-	sigComp := babyjub.SignatureComp(signatureBytes)
-	signature, err := sigComp.Decompress()
+// Verify a batch of PODs, returning whether each one's signature is valid.  A
+// signature which doesn't match, is malformed, or isn't canonical, is a false
+// result.  Any other error, such as a malformed POD, stops verification and is
+// returned with the index of the POD.  Public keys shared by several PODs are
+// only decompressed once.
+func VerifyAll(pods []*Pod) ([]bool, error) {
+	results := make([]bool, len(pods))
+	publicKeys := make(map[string]*babyjub.PublicKey)
+	for i, p := range pods {
+		if p == nil {
+			return nil, fmt.Errorf("POD %d: should not be nil", i)
+		}
+		publicKey, ok := publicKeys[p.SignerPublicKey]
+		if !ok {
+			var err error
+			if publicKey, err = decodePublicKey(p.SignerPublicKey); err != nil {
+				return nil, fmt.Errorf("POD %d: %w", i, err)
+			}
+			publicKeys[p.SignerPublicKey] = publicKey
+		}
+		valid, err := VerifyWithPublicKey(p.Entries, p.Signature, publicKey)
+		if err != nil && !errors.Is(err, ErrNonCanonicalSignature) && !errors.Is(err, ErrMalformedSignature) {
+			return nil, fmt.Errorf("POD %d: %w", i, err)
+		}
+		results[i] = valid
+	}
+	return results, nil
+}
+
+// Validate, decode, and decompress a public key in any supported format.
+func decodePublicKey(encodedPublicKey string) (*babyjub.PublicKey, error) {
+	publicKeyBytes, err := DecodeBytes(encodedPublicKey, 32)
 	if err != nil {
-		return false, fmt.Errorf("failed to decompress signature: %w", err)
+		return nil, fmt.Errorf("%w: failed to decode signer public key: %w", ErrMalformedPublicKey, err)
 	}
 
 	publicKeyComp := babyjub.PublicKeyComp(publicKeyBytes)
 	publicKey, err := publicKeyComp.Decompress()
 	if err != nil {
-		return false, fmt.Errorf("failed to decompress public key: %w", err)
+		return nil, fmt.Errorf("%w: failed to decompress public key: %w", ErrMalformedPublicKey, err)
+	}
+	return publicKey, nil
+}
+
+// Verify an encoded signature on a content ID.
+func verifyContentIDSignature(contentID *big.Int, signature string, publicKey *babyjub.PublicKey) (bool, error) {
+	// Validate and decode signature format
+	signatureBytes, err := DecodeBytes(signature, 64)
+	if err != nil {
+		return false, fmt.Errorf("%w: failed to decode signature: %w", ErrMalformedSignature, err)
+	}
+
+	sigComp := babyjub.SignatureComp(signatureBytes)
+	decompressedSig, err := sigComp.Decompress()
+	if err != nil {
+		return false, fmt.Errorf("%w: failed to decompress signature: %w", ErrMalformedSignature, err)
+	}
+
+	// The babyjub package accepts any S, but S and S plus a multiple of the
+	// subgroup order both verify, so a signature could be altered without the
+	// signer's key.  Only the reduced form is accepted, matching the EdDSA
+	// circuits, so the signature bytes can be used as an identity.
+	if decompressedSig.S.Cmp(babyjub.SubOrder) >= 0 {
+		return false, fmt.Errorf("%w: S is not less than the subgroup order", ErrNonCanonicalSignature)
 	}
 
-	err = publicKey.VerifyPoseidon(contentID, signature)
+	err = publicKey.VerifyPoseidon(contentID, decompressedSig)
 	if err != nil {
 		if !errors.Is(err, babyjub.ErrVerifyPoseidonFailed) {
 			return false, fmt.Errorf("failed to verify signature: %w", err)
@@ -50,3 +163,300 @@ func (p *Pod) Verify() (bool, error) {
 
 	return true, nil
 }
+
+// Verify a POD which commits to external data via the Merkle root held in the
+// named cryptographic entry, then check that blob is a member of that tree.
+//
+// The leaf for blob is hashed the same way as a bytes entry.  Each element of
+// proof is the sibling hash at the next level up as big-endian bytes, and the
+// bits of index (least significant first) say whether the running node is the
+// left (0) or right (1) input to Poseidon at that level.
+func VerifyBlobMembership(p *Pod, rootEntryName string, blob []byte, proof [][]byte, index int) (bool, error) {
+	ok, err := p.Verify()
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	rootValue, err := p.Entries.getTyped(rootEntryName, PodCryptographicValue)
+	if err != nil {
+		return false, err
+	}
+	if index < 0 || index>>len(proof) != 0 {
+		return false, fmt.Errorf("blob index %d out of range for proof of length %d", index, len(proof))
+	}
+
+	node := hashBytes(blob)
+	for level, siblingBytes := range proof {
+		sibling := new(big.Int).SetBytes(siblingBytes)
+		if !utils.CheckBigIntInField(sibling) {
+			return false, fmt.Errorf("proof element %d is not a field element", level)
+		}
+		var inputs []*big.Int
+		if (index>>level)&1 == 0 {
+			inputs = []*big.Int{node, sibling}
+		} else {
+			inputs = []*big.Int{sibling, node}
+		}
+		node, err = poseidon.Hash(inputs)
+		if err != nil {
+			return false, fmt.Errorf("error hashing proof level %d: %w", level, err)
+		}
+	}
+
+	return node.Cmp(rootValue.BigVal) == 0, nil
+}
+
+// Verify a POD, and check that the named eddsa_pubkey entry holds the same key
+// as the POD's signer, regardless of how either key is encoded.  Returns an
+// error wrapping ErrMissingEntry if the binding entry doesn't exist.
+func (p *Pod) VerifyKeyBinding(keyEntryName string) (bool, error) {
+	ok, err := p.Verify()
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	keyValue, err := p.Entries.getTyped(keyEntryName, PodEdDSAPubkeyValue)
+	if err != nil {
+		return false, err
+	}
+	return samePublicKey(keyValue.StringVal, p.SignerPublicKey)
+}
+
+// Verify a POD, and check that the named string entry holds the given nonce,
+// e.g. one issued by a server to prevent a POD being replayed in another
+// context.  Returns an error wrapping ErrMissingEntry if the nonce entry
+// doesn't exist.
+func (p *Pod) VerifyChallenge(nonceEntryName string, nonce string) (bool, error) {
+	ok, err := p.Verify()
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	nonceValue, err := p.Entries.getTyped(nonceEntryName, PodStringValue)
+	if err != nil {
+		return false, err
+	}
+	return nonceValue.StringVal == nonce, nil
+}
+
+// Verify a POD, and check that the named string entry holds one of the allowed
+// values, e.g. a role.  Returns an error wrapping ErrMissingEntry,
+// ErrWrongEntryType, or ErrValueNotAllowed if the entry doesn't match.
+func (p *Pod) VerifyStringOneOf(name string, allowed ...string) (bool, error) {
+	ok, err := p.Verify()
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	value, err := p.Entries.getTyped(name, PodStringValue)
+	if err != nil {
+		return false, err
+	}
+	for _, a := range allowed {
+		if value.StringVal == a {
+			return true, nil
+		}
+	}
+	return false, fmt.Errorf("%w: %q is %q", ErrValueNotAllowed, name, value.StringVal)
+}
+
+// The entries of a template which a POD doesn't match.  Wraps ErrMissingEntry
+// if any entries are missing, and ErrValueNotAllowed if any have a different
+// value.
+type TemplateMismatchError struct {
+	Missing    []string
+	Mismatched []string
+}
+
+func (e *TemplateMismatchError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Mismatched) > 0 {
+		parts = append(parts, "mismatched "+strings.Join(e.Mismatched, ", "))
+	}
+	return "POD does not match template: " + strings.Join(parts, "; ")
+}
+
+func (e *TemplateMismatchError) Unwrap() []error {
+	var errs []error
+	if len(e.Missing) > 0 {
+		errs = append(errs, ErrMissingEntry)
+	}
+	if len(e.Mismatched) > 0 {
+		errs = append(errs, ErrValueNotAllowed)
+	}
+	return errs
+}
+
+// Verify a POD, and check that it contains every entry in the template with an
+// equal value.  Entries not in the template are ignored.  Returns a
+// *TemplateMismatchError listing every template entry which is missing or
+// different.
+func (p *Pod) VerifyContains(template PodEntries) (bool, error) {
+	ok, err := p.Verify()
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	var mismatch TemplateMismatchError
+	for _, name := range template.sortedNames() {
+		value, ok := p.Entries[name]
+		if !ok {
+			mismatch.Missing = append(mismatch.Missing, name)
+		} else if !value.Equal(template[name]) {
+			mismatch.Mismatched = append(mismatch.Mismatched, name)
+		}
+	}
+	if len(mismatch.Missing) > 0 || len(mismatch.Mismatched) > 0 {
+		return false, &mismatch
+	}
+	return true, nil
+}
+
+// A set of trusted signer public keys, identified by a version so that audit
+// logs can record which trust configuration accepted a POD.
+type VersionedKeySet struct {
+	Version string
+	keys    map[[32]byte]bool
+}
+
+// Create a key set with the given version, from public keys encoded as hex or
+// Base64.
+func NewVersionedKeySet(version string, publicKeys []string) (*VersionedKeySet, error) {
+	keys := make(map[[32]byte]bool, len(publicKeys))
+	for _, publicKey := range publicKeys {
+		publicKeyBytes, err := DecodeBytes(publicKey, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode public key '%s': %w", publicKey, err)
+		}
+		keys[[32]byte(publicKeyBytes)] = true
+	}
+	return &VersionedKeySet{Version: version, keys: keys}, nil
+}
+
+// Checks whether the given encoded public key is in this set.
+func (v *VersionedKeySet) Contains(publicKey string) bool {
+	publicKeyBytes, err := DecodeBytes(publicKey, 32)
+	if err != nil {
+		return false
+	}
+	return v.keys[[32]byte(publicKeyBytes)]
+}
+
+// Verify a POD signed by one of the keys in this set.  On success, returns the
+// version of the set which accepted it.  Returns an error wrapping
+// ErrUntrustedSigner if the POD's signer isn't in the set.
+func (v *VersionedKeySet) Verify(p *Pod) (version string, ok bool, err error) {
+	if !v.Contains(p.SignerPublicKey) {
+		return "", false, fmt.Errorf("%w: %s not in key set version %s", ErrUntrustedSigner, p.SignerPublicKey, v.Version)
+	}
+	ok, err = p.Verify()
+	if err != nil || !ok {
+		return "", ok, err
+	}
+	return v.Version, true, nil
+}
+
+// Verify a POD, and check that the named date entry is after now.  Returns an
+// error wrapping ErrMissingExpiry if the entry doesn't exist, or ErrExpired if
+// now is at or after the expiration date.
+func (p *Pod) VerifyNotExpired(expiryEntryName string, now time.Time) (bool, error) {
+	ok, err := p.Verify()
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	if _, ok := p.Entries[expiryEntryName]; !ok {
+		return false, fmt.Errorf("%w: %q", ErrMissingExpiry, expiryEntryName)
+	}
+	expiryValue, err := p.Entries.getTyped(expiryEntryName, PodDateValue)
+	if err != nil {
+		return false, err
+	}
+	if !now.Before(expiryValue.TimeVal) {
+		return false, fmt.Errorf("%w: %q was %v", ErrExpired, expiryEntryName, expiryValue.TimeVal)
+	}
+	return true, nil
+}
+
+// Verify a POD, and check that the named bytes entry is a commitment to the
+// given document.  By convention the commitment is the 32-byte SHA-256 digest
+// of the document, which is compared in constant time.
+func (p *Pod) VerifyBytesCommitment(name string, document []byte) (bool, error) {
+	ok, err := p.Verify()
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	commitmentValue, err := p.Entries.getTyped(name, PodBytesValue)
+	if err != nil {
+		return false, err
+	}
+	digest := sha256.Sum256(document)
+	return subtle.ConstantTimeCompare(commitmentValue.BytesVal, digest[:]) == 1, nil
+}
+
+// Verify a POD issued by a signer which was authorized by a root key.  The
+// authorizer POD must be signed by rootKey, and must contain the leaf POD's
+// signer as one of its eddsa_pubkey entries.  Returns an error wrapping
+// ErrUntrustedSigner if either signer isn't authorized.
+func VerifyWithTrustChain(leaf *Pod, authorizerPOD *Pod, rootKey string) (bool, error) {
+	rootSigned, err := samePublicKey(authorizerPOD.SignerPublicKey, rootKey)
+	if err != nil {
+		return false, err
+	}
+	if !rootSigned {
+		return false, fmt.Errorf("%w: authorizer POD not signed by root key", ErrUntrustedSigner)
+	}
+	ok, err := authorizerPOD.Verify()
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	authorized := false
+	for _, value := range authorizerPOD.Entries {
+		if value.ValueType != PodEdDSAPubkeyValue {
+			continue
+		}
+		if authorized, err = samePublicKey(value.StringVal, leaf.SignerPublicKey); err != nil {
+			return false, err
+		}
+		if authorized {
+			break
+		}
+	}
+	if !authorized {
+		return false, fmt.Errorf("%w: %s not authorized by authorizer POD", ErrUntrustedSigner, leaf.SignerPublicKey)
+	}
+
+	return leaf.Verify()
+}
+
+// Checks whether two encoded public keys hold the same key, regardless of
+// whether they're encoded as hex or Base64.
+func samePublicKey(a string, b string) (bool, error) {
+	aBytes, err := DecodeBytes(a, 32)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode public key '%s': %w", a, err)
+	}
+	bBytes, err := DecodeBytes(b, 32)
+	if err != nil {
+		return false, fmt.Errorf("failed to decode public key '%s': %w", b, err)
+	}
+	return bytes.Equal(aBytes, bBytes), nil
+}
+
+// Look up the named entry, returning an error wrapping ErrMissingEntry or
+// ErrWrongEntryType if it doesn't exist or isn't of the given type.
+func (p PodEntries) getTyped(name string, valueType PodValueType) (PodValue, error) {
+	value, ok := p[name]
+	if !ok {
+		return PodValue{}, fmt.Errorf("%w: %q", ErrMissingEntry, name)
+	}
+	if value.ValueType != valueType {
+		return PodValue{}, fmt.Errorf("%w: %q must be %s, not %s", ErrWrongEntryType, name, valueType, value.ValueType)
+	}
+	return value, nil
+}
@@ -0,0 +1,24 @@
+package pod
+
+// Version of this library, recorded in attestation bundles.  Release builds
+// can override it with:
+//
+//	-ldflags "-X github.com/0xPARC/parcnet/go/pod.version=v1.2.3"
+var version = "dev"
+
+// Version of the POD format produced and accepted by this library: the JSON
+// encoding shared with @pcd/pod, content IDs computed as a binary lean
+// Poseidon IMT of name and value hashes, and EdDSA-Poseidon signatures on
+// BabyJubJub.
+const specVersion = "1"
+
+// Returns the version of this library, which is "dev" unless set at build
+// time.
+func Version() string {
+	return version
+}
+
+// Returns the version of the POD format this library targets.
+func SpecVersion() string {
+	return specVersion
+}